@@ -6,6 +6,8 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"syscall"
 	"time"
 
 	"github.com/wendall-robinson/flowmaster/traceflow"
@@ -15,13 +17,25 @@ func main() {
 	// Initialize the OpenTelemetry tracing system
 	ctx := context.Background()
 
-	// Initialize OpenTelemetry with OTLP export to a collector running on localhost:4317
-	ctx, shutdown, err := traceflow.Init(ctx, "basic-service", traceflow.WithOLTP("otel:4317"))
+	// Initialize OpenTelemetry with OTLP export to a collector running on localhost:4317.
+	// If the job is interrupted, the spans ended so far are still exported before it exits.
+	ctx, shutdown, err := traceflow.Init(ctx, "basic-service",
+		traceflow.WithOLTP("otel:4317"),
+		traceflow.WithShutdownOnSignal(os.Interrupt, syscall.SIGTERM),
+	)
 	if err != nil {
 		log.Fatalf("Failed to initialize OpenTelemetry: %v", err)
 	}
 
-	defer shutdown(ctx)
+	// Shutting down exports the remaining spans, so the job can exit as soon as it is done
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down OpenTelemetry: %v", err)
+		}
+	}()
 
 	fmt.Println("Application is running...")
 
@@ -48,10 +62,12 @@ func main() {
 	// End the main span when all other spans are complete
 	trace.End()
 
-	// Prevent the app from exiting immediately
-	for {
-		time.Sleep(30 * time.Minute)
+	// Export the spans now rather than waiting for the next batch, e.g. between jobs of a worker
+	if err := traceflow.Flush(ctx); err != nil {
+		log.Printf("Failed to flush spans: %v", err)
 	}
+
+	fmt.Println("Application finished")
 }
 
 func traceIf(ctx context.Context, test bool) {
@@ -64,25 +80,22 @@ func sendHTTPRequest(ctx context.Context) {
 	// The URL of the endpoint
 	url := "http://web:8080/test"
 
-	// Create a new GET request
-	req, err := http.NewRequest("GET", url, nil)
+	// Create a new GET request, be sure to pass in the trace context to propagate the trace
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		log.Fatalf("Failed to create request: %s", err)
 	}
 
-	// Create a new traceflow instance with the context and service name
-	trace := traceflow.New(ctx, "http-client").Client()
-
-	// Start the span and inject the trace context into the request
-	// be sure to inject the context after starting the span
-	defer trace.Start("http-request").InjectHTTPContext(req).End()
+	// The traceflow transport starts a client span for every request and injects
+	// the trace context into the request headers
+	client := &http.Client{Transport: traceflow.NewTransport(nil)}
 
-	// Perform the request using http.DefaultClient
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		log.Fatalf("Failed to make request: %s", err)
 	}
 
+	// The client span ends when the response body is closed
 	defer resp.Body.Close()
 
 	// Read the response body
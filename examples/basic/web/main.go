@@ -5,24 +5,31 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"syscall"
 
 	"github.com/wendall-robinson/flowmaster/traceflow"
 )
 
 func testHandler(w http.ResponseWriter, r *http.Request) {
-	trace := traceflow.Now(r.Context(), "http-handler", "testing-endpoint", traceflow.WithHTTPContext(r))
-	defer trace.End()
+	// The middleware has already started a server span for this request
+	if trace, ok := traceflow.FromContext(r.Context()); ok {
+		trace.AddAttribute(traceflow.AddString("handler", "testing-endpoint"))
+	}
 
 	fmt.Fprintf(w, "hello world!")
-
 }
 
 func main() {
 	// Initialize the OpenTelemetry tracing system
 	ctx := context.Background()
 
-	// Initialize OpenTelemetry with OTLP export to a collector running on localhost:4317
-	ctx, shutdown, err := traceflow.Init(ctx, "web-service", traceflow.WithOLTP("otel:4317"))
+	// Initialize OpenTelemetry with OTLP export to a collector running on localhost:4317.
+	// When the container is stopped, the queued spans are exported before the server exits.
+	ctx, shutdown, err := traceflow.Init(ctx, "web-service",
+		traceflow.WithOLTP("otel:4317"),
+		traceflow.WithShutdownOnSignal(os.Interrupt, syscall.SIGTERM),
+	)
 	if err != nil {
 		log.Fatalf("Failed to initialize OpenTelemetry: %v", err)
 	}
@@ -32,11 +39,12 @@ func main() {
 	fmt.Println("Web Service is running...")
 
 	// Set up the /test endpoint
-	http.HandleFunc("/test", testHandler)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /test", testHandler)
 
-	// Run the server on port 8080
+	// Run the server on port 8080, tracing every request
 	fmt.Println("Server running on port 8080...")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
+	if err := http.ListenAndServe(":8080", traceflow.Middleware("http-handler")(mux)); err != nil {
 		log.Fatalf("Could not start server: %s\n", err)
 	}
 }
@@ -92,7 +92,7 @@ func AddBoolSlice(key string, value []bool) Attribute {
 func (t *Trace) AddJSON(payload json.RawMessage) *Trace {
 	jsonString := string(payload)
 	jsonAttr := attribute.String("payload", jsonString)
-	t.attrs = append(t.attrs, jsonAttr)
+	t.setAttributes(jsonAttr)
 
 	return t
 }
@@ -5,7 +5,6 @@ import (
 	"os"
 	"runtime"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 )
@@ -75,7 +74,7 @@ func WithEnVars(keys []string) Option {
 //	}
 func WithHTTPContext(req *http.Request) Option {
 	return func(t *Trace) {
-		propagator := otel.GetTextMapPropagator()
+		propagator := t.telemetry.Propagator()
 		ctx := propagator.Extract(t.ctx, propagation.HeaderCarrier(req.Header))
 		t.ctx = ctx
 	}
@@ -0,0 +1,32 @@
+package traceflow
+
+import "context"
+
+// traceContextKey is the context key under which a *Trace is stored.
+type traceContextKey struct{}
+
+// ContextWithTrace returns a copy of ctx that carries the given Trace. Handlers further
+// down the call chain can retrieve it with FromContext.
+func ContextWithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, t)
+}
+
+// FromContext returns the Trace stored in ctx by ContextWithTrace or by one of the
+// traceflow middlewares, and reports whether one was found.
+//
+// Example usage:
+//
+//	func MyHandler(w http.ResponseWriter, r *http.Request) {
+//	    if trace, ok := traceflow.FromContext(r.Context()); ok {
+//	        trace.AddUser(userID, username)
+//	    }
+//	}
+func FromContext(ctx context.Context) (*Trace, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	t, ok := ctx.Value(traceContextKey{}).(*Trace)
+
+	return t, ok && t != nil
+}
@@ -4,7 +4,7 @@ import "go.opentelemetry.io/otel/attribute"
 
 // AddDBQuery adds database query information to the trace.
 func (t *Trace) AddDBQuery(query, dbType string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.query", query),
 		attribute.String("db.system", dbType),
 	)
@@ -14,7 +14,7 @@ func (t *Trace) AddDBQuery(query, dbType string) *Trace {
 
 // AddDBInfo adds database-related attributes like database name and version.
 func (t *Trace) AddDBInfo(dbName, dbVersion string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.name", dbName),
 		attribute.String("db.version", dbVersion),
 	)
@@ -24,7 +24,7 @@ func (t *Trace) AddDBInfo(dbName, dbVersion string) *Trace {
 
 // AddDBConnectionInfo adds database connection-related attributes like connection string and connection count.
 func (t *Trace) AddDBConnectionInfo(connectionString string, connectionCount int) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.connection_string", connectionString),
 		attribute.Int("db.connection_count", connectionCount),
 	)
@@ -34,7 +34,7 @@ func (t *Trace) AddDBConnectionInfo(connectionString string, connectionCount int
 
 // AddDBTableInfo adds database table-related attributes like table name and row count.
 func (t *Trace) AddDBTableInfo(tableName string, rowCount int) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.table_name", tableName),
 		attribute.Int("db.row_count", rowCount),
 	)
@@ -44,7 +44,7 @@ func (t *Trace) AddDBTableInfo(tableName string, rowCount int) *Trace {
 
 // AddDBIndexInfo adds database index-related attributes like index name and index count.
 func (t *Trace) AddDBIndexInfo(indexName string, indexCount int) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.index_name", indexName),
 		attribute.Int("db.index_count", indexCount),
 	)
@@ -54,7 +54,7 @@ func (t *Trace) AddDBIndexInfo(indexName string, indexCount int) *Trace {
 
 // AddDBColumnInfo adds database column-related attributes like column name and column count.
 func (t *Trace) AddDBColumnInfo(columnName string, columnCount int) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.column_name", columnName),
 		attribute.Int("db.column_count", columnCount),
 	)
@@ -64,7 +64,7 @@ func (t *Trace) AddDBColumnInfo(columnName string, columnCount int) *Trace {
 
 // AddDBTransactionInfo adds database transaction-related attributes like transaction ID and status.
 func (t *Trace) AddDBTransactionInfo(transactionID, status string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.transaction_id", transactionID),
 		attribute.String("db.transaction_status", status),
 	)
@@ -74,7 +74,7 @@ func (t *Trace) AddDBTransactionInfo(transactionID, status string) *Trace {
 
 // AddDBErrorInfo adds database error-related attributes like error message and error code.
 func (t *Trace) AddDBErrorInfo(errorMessage, errorCode string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("db.error_message", errorMessage),
 		attribute.String("db.error_code", errorCode),
 	)
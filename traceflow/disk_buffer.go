@@ -0,0 +1,482 @@
+package traceflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// spoolExt is the extension of the files holding buffered batches.
+	spoolExt = ".spool"
+
+	// defaultBufferMaxSize is the default disk space used by a BufferedExporter.
+	defaultBufferMaxSize = 64 << 20
+
+	// defaultBufferInitialBackoff and defaultBufferMaxBackoff bound the delay between
+	// attempts to replay buffered batches.
+	defaultBufferInitialBackoff = time.Second
+	defaultBufferMaxBackoff     = time.Minute
+)
+
+// BufferOption defines a functional option for configuring the disk buffer created by
+// NewBufferedExporter, WithDiskBuffer and WithOTLPDiskBuffer.
+type BufferOption func(*bufferConfig)
+
+// bufferConfig holds the settings of a BufferedExporter.
+type bufferConfig struct {
+	maxSize        int64
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// WithBufferMaxSize limits the disk space used by buffered spans to maxBytes. When the limit
+// is reached the oldest batches are dropped first. The default is 64 MiB.
+func WithBufferMaxSize(maxBytes int64) BufferOption {
+	return func(c *bufferConfig) {
+		c.maxSize = maxBytes
+	}
+}
+
+// WithBufferBackoff sets the delay between attempts to replay buffered spans. The delay
+// starts at initial and doubles after every failed attempt up to maxBackoff. The defaults
+// are one second and one minute.
+func WithBufferBackoff(initial, maxBackoff time.Duration) BufferOption {
+	return func(c *bufferConfig) {
+		c.initialBackoff = initial
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// spoolBatch is a batch of spans waiting on disk to be exported.
+type spoolBatch struct {
+	path  string
+	size  int64
+	spans int
+}
+
+// BufferedExporter wraps a span exporter and spools batches it fails to export to a bounded
+// queue on disk. Buffered batches are replayed in order, with backoff, once the wrapped
+// exporter recovers, and survive restarts of the process.
+type BufferedExporter struct {
+	exporter sdktrace.SpanExporter
+	dir      string
+	config   bufferConfig
+
+	mu       sync.Mutex
+	batches  []spoolBatch
+	size     int64
+	seq      uint64
+	dropped  int64
+	shutdown bool
+
+	// exportMu serializes calls to the wrapped exporter between ExportSpans and the replay loop
+	exportMu sync.Mutex
+
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewBufferedExporter wraps exporter with a disk buffer in dir. Batches left in dir by a
+// previous run are replayed.
+//
+// Example usage:
+//
+//	exporter, err := traceflow.NewBufferedExporter(otlpExporter, "/var/lib/my-service/spans",
+//	    traceflow.WithBufferMaxSize(256<<20),
+//	)
+func NewBufferedExporter(exporter sdktrace.SpanExporter, dir string, opts ...BufferOption) (*BufferedExporter, error) {
+	const dirmode = 0o755
+
+	e := &BufferedExporter{
+		exporter: exporter,
+		dir:      dir,
+		config: bufferConfig{
+			maxSize:        defaultBufferMaxSize,
+			initialBackoff: defaultBufferInitialBackoff,
+			maxBackoff:     defaultBufferMaxBackoff,
+		},
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(&e.config)
+	}
+
+	if err := os.MkdirAll(dir, dirmode); err != nil {
+		return nil, err
+	}
+
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+
+	go e.run()
+
+	if len(e.batches) > 0 {
+		e.signal()
+	}
+
+	return e, nil
+}
+
+// ExportSpans exports the spans, or buffers them on disk if the wrapped exporter fails or
+// earlier batches are still waiting to be replayed. It only returns an error if the spans
+// could not be buffered.
+func (e *BufferedExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	if e.shutdown {
+		e.mu.Unlock()
+		return errors.ErrExporterShutdown
+	}
+
+	pending := len(e.batches) > 0
+	e.mu.Unlock()
+
+	// Queue behind pending batches to keep spans in order and not hammer a failing backend
+	if !pending && e.export(ctx, spans) == nil {
+		return nil
+	}
+
+	if err := e.spool(spans); err != nil {
+		return err
+	}
+
+	e.signal()
+
+	return nil
+}
+
+// Shutdown stops replaying, makes a last attempt to export the buffered spans within ctx,
+// and shuts down the wrapped exporter. Spans that still cannot be exported stay on disk.
+func (e *BufferedExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if e.shutdown {
+		e.mu.Unlock()
+		return nil
+	}
+
+	e.shutdown = true
+	e.mu.Unlock()
+
+	close(e.done)
+	<-e.stopped
+
+	_ = e.drain(ctx)
+
+	return e.exporter.Shutdown(ctx)
+}
+
+// Dropped returns the number of spans discarded because the disk buffer was full or a
+// buffered batch could not be read back.
+func (e *BufferedExporter) Dropped() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.dropped
+}
+
+// Pending returns the number of spans buffered on disk.
+func (e *BufferedExporter) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pending := 0
+	for _, batch := range e.batches {
+		pending += batch.spans
+	}
+
+	return pending
+}
+
+// export sends the spans to the wrapped exporter.
+func (e *BufferedExporter) export(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.exportMu.Lock()
+	defer e.exportMu.Unlock()
+
+	return e.exporter.ExportSpans(ctx, spans)
+}
+
+// signal wakes the replay loop.
+func (e *BufferedExporter) signal() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run replays buffered batches until the exporter is shut down, backing off while the
+// wrapped exporter keeps failing.
+func (e *BufferedExporter) run() {
+	defer close(e.stopped)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-e.done
+		cancel()
+	}()
+
+	backoff := e.config.initialBackoff
+
+	var retry <-chan time.Time
+
+	for {
+		// New batches do not cut a backoff short
+		wake := e.wake
+		if retry != nil {
+			wake = nil
+		}
+
+		select {
+		case <-e.done:
+			return
+		case <-wake:
+		case <-retry:
+		}
+
+		if err := e.drain(ctx); err != nil {
+			retry = time.After(backoff)
+			backoff = min(backoff*2, e.config.maxBackoff) //nolint:mnd
+
+			continue
+		}
+
+		retry = nil
+		backoff = e.config.initialBackoff
+	}
+}
+
+// drain exports buffered batches, oldest first, until the buffer is empty or an export fails.
+func (e *BufferedExporter) drain(ctx context.Context) error {
+	for {
+		e.mu.Lock()
+		if len(e.batches) == 0 {
+			e.mu.Unlock()
+			return nil
+		}
+
+		batch := e.batches[0]
+		e.mu.Unlock()
+
+		spans, err := readSpoolFile(batch.path)
+		if err != nil {
+			// A batch that cannot be read back would block the queue forever
+			otel.Handle(fmt.Errorf("%w: %s: %w", errors.ErrSpansDropped, batch.path, err))
+			e.remove(batch, true)
+
+			continue
+		}
+
+		if err := e.export(ctx, spans); err != nil {
+			return err
+		}
+
+		e.remove(batch, false)
+	}
+}
+
+// remove deletes the batch from the queue, counting its spans as dropped if requested. It
+// does nothing if the batch was already evicted.
+func (e *BufferedExporter) remove(batch spoolBatch, dropped bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.batches) == 0 || e.batches[0].path != batch.path {
+		return
+	}
+
+	e.removeOldest()
+
+	if dropped {
+		e.dropped += int64(batch.spans)
+	}
+}
+
+// removeOldest deletes the oldest batch from the queue and the disk. The caller must hold
+// e.mu.
+func (e *BufferedExporter) removeOldest() spoolBatch {
+	batch := e.batches[0]
+
+	e.batches = e.batches[1:]
+	e.size -= batch.size
+
+	if err := os.Remove(batch.path); err != nil && !os.IsNotExist(err) {
+		otel.Handle(err)
+	}
+
+	return batch
+}
+
+// spool writes the spans to a new batch file, evicting the oldest batches to stay within
+// the size limit.
+func (e *BufferedExporter) spool(spans []sdktrace.ReadOnlySpan) error {
+	const filemode = 0o644
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+
+	for _, span := range spans {
+		record, err := newSpanRecord(span)
+		if err != nil {
+			return err
+		}
+
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	size := int64(buf.Len())
+	if size > e.config.maxSize {
+		e.dropped += int64(len(spans))
+		otel.Handle(fmt.Errorf("%w: batch of %d spans exceeds the buffer size", errors.ErrSpansDropped, len(spans)))
+
+		return nil
+	}
+
+	evicted := 0
+	for len(e.batches) > 0 && e.size+size > e.config.maxSize {
+		evicted += e.removeOldest().spans
+	}
+
+	if evicted > 0 {
+		e.dropped += int64(evicted)
+		otel.Handle(fmt.Errorf("%w: evicted %d buffered spans", errors.ErrSpansDropped, evicted))
+	}
+
+	e.seq++
+	path := filepath.Join(e.dir, fmt.Sprintf("%020d-%d%s", e.seq, len(spans), spoolExt))
+
+	// Write to a temporary file first so that a crash never leaves a partial batch behind
+	if err := os.WriteFile(path+".tmp", buf.Bytes(), filemode); err != nil {
+		return err
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	e.batches = append(e.batches, spoolBatch{path: path, size: size, spans: len(spans)})
+	e.size += size
+
+	return nil
+}
+
+// load queues the batches left in the directory by a previous run and removes partially
+// written ones.
+func (e *BufferedExporter) load() error {
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(e.dir, entry.Name())
+
+		if strings.HasSuffix(entry.Name(), spoolExt+".tmp") {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		seq, spans, ok := parseSpoolName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		e.batches = append(e.batches, spoolBatch{path: path, size: info.Size(), spans: spans})
+		e.size += info.Size()
+		e.seq = max(e.seq, seq)
+	}
+
+	slices.SortFunc(e.batches, func(a, b spoolBatch) int {
+		return strings.Compare(a.path, b.path)
+	})
+
+	return nil
+}
+
+// parseSpoolName parses a batch file name of the form "<sequence>-<span count>.spool".
+func parseSpoolName(name string) (seq uint64, spans int, ok bool) {
+	base, found := strings.CutSuffix(name, spoolExt)
+	if !found {
+		return 0, 0, false
+	}
+
+	seqPart, spansPart, found := strings.Cut(base, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	spans, err = strconv.Atoi(spansPart)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return seq, spans, true
+}
+
+// readSpoolFile decodes the spans in a batch file.
+func readSpoolFile(path string) ([]sdktrace.ReadOnlySpan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var spans []sdktrace.ReadOnlySpan
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	for decoder.More() {
+		var record spanRecord
+
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("%w: %w", errors.ErrInvalidSpanRecord, err)
+		}
+
+		span, err := record.snapshot()
+		if err != nil {
+			return nil, err
+		}
+
+		spans = append(spans, span)
+	}
+
+	return spans, nil
+}
+
+// Ensure BufferedExporter implements sdktrace.SpanExporter
+var _ sdktrace.SpanExporter = (*BufferedExporter)(nil)
@@ -0,0 +1,240 @@
+package traceflow
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tferrors "github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// unreachableExporter records span names and fails while it is down.
+type unreachableExporter struct {
+	down  atomic.Bool
+	mu    sync.Mutex
+	names []string
+}
+
+func (e *unreachableExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if e.down.Load() {
+		return errExportFailed
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range spans {
+		e.names = append(e.names, span.Name())
+	}
+
+	return nil
+}
+
+func (e *unreachableExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// exported returns the names of the exported spans.
+func (e *unreachableExporter) exported() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]string(nil), e.names...)
+}
+
+// silenceErrorHandler discards errors reported to otel.Handle for the duration of the test.
+func silenceErrorHandler(t *testing.T) {
+	t.Helper()
+
+	previous := otel.GetErrorHandler()
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+	t.Cleanup(func() { otel.SetErrorHandler(previous) })
+}
+
+// bufferSpans exports one span per name through the buffered exporter.
+func bufferSpans(t *testing.T, exporter *BufferedExporter, names ...string) {
+	t.Helper()
+
+	stubs := make(tracetest.SpanStubs, len(names))
+	for i, name := range names {
+		stubs[i] = tracetest.SpanStub{Name: name, SpanContext: testSpanContext(t)}
+	}
+
+	require.NoError(t, exporter.ExportSpans(context.Background(), stubs.Snapshots()))
+}
+
+// TestBufferedExporterReplay tests that spans are buffered while the exporter is down and
+// replayed in order once it recovers.
+func TestBufferedExporterReplay(t *testing.T) {
+	downstream := &unreachableExporter{}
+	downstream.down.Store(true)
+
+	exporter, err := NewBufferedExporter(downstream, t.TempDir(), WithBufferBackoff(10*time.Millisecond, 20*time.Millisecond))
+	require.NoError(t, err)
+
+	bufferSpans(t, exporter, "first", "second")
+	bufferSpans(t, exporter, "third")
+
+	assert.Equal(t, 3, exporter.Pending())
+
+	downstream.down.Store(false)
+
+	assert.Eventually(t, func() bool {
+		return exporter.Pending() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	bufferSpans(t, exporter, "fourth")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"first", "second", "third", "fourth"}, downstream.exported())
+	assert.Zero(t, exporter.Dropped())
+}
+
+// TestBufferedExporterEviction tests that the oldest batches are dropped once the buffer is
+// full and that the dropped spans are counted.
+func TestBufferedExporterEviction(t *testing.T) {
+	silenceErrorHandler(t)
+
+	downstream := &unreachableExporter{}
+	downstream.down.Store(true)
+
+	dir := t.TempDir()
+
+	exporter, err := NewBufferedExporter(downstream, dir, WithBufferBackoff(time.Hour, time.Hour))
+	require.NoError(t, err)
+
+	bufferSpans(t, exporter, "span-1")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+
+	// Room for two single span batches
+	exporter.config.maxSize = 2 * info.Size()
+
+	bufferSpans(t, exporter, "span-2")
+	bufferSpans(t, exporter, "span-3")
+	bufferSpans(t, exporter, "span-4", "span-5", "span-6")
+
+	assert.Equal(t, int64(4), exporter.Dropped())
+	assert.Equal(t, 2, exporter.Pending())
+
+	downstream.down.Store(false)
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"span-2", "span-3"}, downstream.exported())
+}
+
+// TestBufferedExporterRestart tests that spans buffered by a previous run are replayed.
+func TestBufferedExporterRestart(t *testing.T) {
+	dir := t.TempDir()
+	downstream := &unreachableExporter{}
+	downstream.down.Store(true)
+
+	exporter, err := NewBufferedExporter(downstream, dir, WithBufferBackoff(time.Hour, time.Hour))
+	require.NoError(t, err)
+
+	bufferSpans(t, exporter, "first")
+	bufferSpans(t, exporter, "second")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	assert.Empty(t, downstream.exported())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000003-1.spool.tmp"), []byte("{"), 0o600))
+
+	downstream.down.Store(false)
+
+	exporter, err = NewBufferedExporter(downstream, dir)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(downstream.exported()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	bufferSpans(t, exporter, "third")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"first", "second", "third"}, downstream.exported())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "Expected replayed and partial batches to be removed")
+}
+
+// TestWithDiskBuffer tests that spans ended while the exporter is down reach it through Init.
+func TestWithDiskBuffer(t *testing.T) {
+	downstream := &unreachableExporter{}
+	downstream.down.Store(true)
+
+	previous := otel.GetTracerProvider()
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithSpanExporter(downstream, WithDiskBuffer(t.TempDir(), WithBufferBackoff(10*time.Millisecond, 10*time.Millisecond))),
+	)
+	require.NoError(t, err)
+
+	New(ctx, "test-service").Start("buffered").End()
+	require.NoError(t, otel.GetTracerProvider().(*sdktrace.TracerProvider).ForceFlush(ctx))
+
+	downstream.down.Store(false)
+	require.NoError(t, shutdown(ctx))
+
+	assert.Equal(t, []string{"test-service.buffered"}, downstream.exported())
+}
+
+// TestWithDiskBufferReportsDrops tests that spans the buffer drops through Init are reported
+// to the OpenTelemetry error handler, since the BufferedExporter is not exposed there.
+func TestWithDiskBufferReportsDrops(t *testing.T) {
+	downstream := &unreachableExporter{}
+	downstream.down.Store(true)
+
+	var (
+		mu      sync.Mutex
+		handled []error
+	)
+
+	previousHandler := otel.GetErrorHandler()
+	previous := otel.GetTracerProvider()
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled = append(handled, err)
+	}))
+	t.Cleanup(func() {
+		otel.SetErrorHandler(previousHandler)
+		otel.SetTracerProvider(previous)
+	})
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithSpanExporter(downstream, WithDiskBuffer(t.TempDir(), WithBufferMaxSize(1))),
+	)
+	require.NoError(t, err)
+
+	New(ctx, "test-service").Start("dropped").End()
+	require.NoError(t, otel.GetTracerProvider().(*sdktrace.TracerProvider).ForceFlush(ctx))
+	require.NoError(t, shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.ErrorIs(t, stderrors.Join(handled...), tferrors.ErrSpansDropped)
+}
@@ -0,0 +1,263 @@
+package traceflow
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Standard OpenTelemetry environment variables honored by Init.
+const (
+	envSDKDisabled        = "OTEL_SDK_DISABLED"
+	envServiceName        = "OTEL_SERVICE_NAME"
+	envResourceAttributes = "OTEL_RESOURCE_ATTRIBUTES"
+	envExporterEndpoint   = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	envExporterProtocol   = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	envExporterHeaders    = "OTEL_EXPORTER_OTLP_HEADERS"
+	envTracesSampler      = "OTEL_TRACES_SAMPLER"
+	envTracesSamplerArg   = "OTEL_TRACES_SAMPLER_ARG"
+	envPropagators        = "OTEL_PROPAGATORS"
+)
+
+// defaultServiceName is used when neither Init nor OTEL_SERVICE_NAME names the service.
+const defaultServiceName = "unknown_service"
+
+// envConfig holds the configuration read from the OTEL_* environment variables.
+type envConfig struct {
+	disabled      bool
+	serviceName   string
+	resourceAttrs []attribute.KeyValue
+	endpoint      string
+	protocol      string
+	headers       map[string]string
+	sampler       sdktrace.Sampler
+	propagators   []propagation.TextMapPropagator
+}
+
+// InitFromEnv initializes OpenTelemetry entirely from the standard OTEL_* environment
+// variables, taking the service name from OTEL_SERVICE_NAME. Options passed to
+// InitFromEnv take precedence over the environment.
+//
+// Example usage:
+//
+//	// OTEL_SERVICE_NAME=checkout OTEL_EXPORTER_OTLP_ENDPOINT=http://otel:4318
+//	ctx, shutdown, err := traceflow.InitFromEnv(ctx)
+//	if err != nil {
+//	    log.Fatalf("Failed to initialize OpenTelemetry: %v", err)
+//	}
+//	defer shutdown(ctx)
+func InitFromEnv(ctx context.Context, opts ...InitOption) (context.Context, func(context.Context) error, error) {
+	return Init(ctx, "", opts...)
+}
+
+// loadEnv reads the OTEL_* environment variables, recording invalid values as option errors.
+func (tb *TelemetryBuilder) loadEnv() {
+	env := envConfig{
+		serviceName: strings.TrimSpace(os.Getenv(envServiceName)),
+		endpoint:    strings.TrimSpace(os.Getenv(envExporterEndpoint)),
+		protocol:    strings.TrimSpace(os.Getenv(envExporterProtocol)),
+	}
+
+	if value := strings.TrimSpace(os.Getenv(envSDKDisabled)); value != "" {
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			tb.addEnvError(envSDKDisabled, value, err)
+		}
+
+		env.disabled = disabled
+	}
+
+	if value := os.Getenv(envResourceAttributes); value != "" {
+		attrs, err := parseEnvList(value)
+		if err != nil {
+			tb.addEnvError(envResourceAttributes, value, err)
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(attrs)) {
+			env.resourceAttrs = append(env.resourceAttrs, attribute.String(key, attrs[key]))
+		}
+	}
+
+	if value := os.Getenv(envExporterHeaders); value != "" {
+		headers, err := parseEnvList(value)
+		if err != nil {
+			tb.addEnvError(envExporterHeaders, value, err)
+		}
+
+		env.headers = headers
+	}
+
+	switch env.protocol {
+	case "", "grpc", "http/protobuf":
+	default:
+		tb.addEnvError(envExporterProtocol, env.protocol, errors.ErrUnsupportedProtocol)
+	}
+
+	if value := strings.TrimSpace(os.Getenv(envTracesSampler)); value != "" {
+		ratio, err := parseSamplerArg(strings.TrimSpace(os.Getenv(envTracesSamplerArg)))
+		if err != nil {
+			tb.addEnvError(envTracesSamplerArg, os.Getenv(envTracesSamplerArg), err)
+		}
+
+		sampler, err := samplerByName(value, ratio)
+		if err != nil {
+			tb.addEnvError(envTracesSampler, value, err)
+		}
+
+		env.sampler = sampler
+	}
+
+	if value := strings.TrimSpace(os.Getenv(envPropagators)); value != "" {
+		propagators, err := propagatorsByName(strings.Split(value, ",")...)
+		if err != nil {
+			tb.addEnvError(envPropagators, value, err)
+		}
+
+		env.propagators = propagators
+	}
+
+	tb.env = env
+}
+
+// applyEnv fills in any configuration not set by an explicit InitOption from the
+// environment.
+func (tb *TelemetryBuilder) applyEnv() {
+	if tb.sampler == nil && !tb.useParent {
+		tb.sampler = tb.env.sampler
+	}
+
+	if tb.propagators == nil {
+		tb.propagators = tb.env.propagators
+	}
+
+	// Explicit resource attributes come last so they win over the environment
+	tb.resourceAttrs = append(slices.Clone(tb.env.resourceAttrs), tb.resourceAttrs...)
+
+	if len(tb.exporters) > 0 || tb.exporter != nil || tb.env.endpoint == "" {
+		return
+	}
+
+	var (
+		exp sdktrace.SpanExporter
+		err error
+	)
+
+	opts := []OTLPOption{WithOTLPHeaders(tb.env.headers)}
+
+	if tb.env.protocol == "grpc" {
+		target := tb.env.endpoint
+
+		if u, parseErr := url.Parse(target); parseErr == nil && u.Host != "" {
+			target = u.Host
+
+			if u.Scheme == "https" {
+				opts = append(opts, WithOTLPTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+			}
+		}
+
+		exp, err = newOTLPGRPCExporter(tb.ctx, target, opts)
+	} else {
+		// http/protobuf is the default protocol. The endpoint is a base URL; traces are sent to its /v1/traces path
+		if u, parseErr := url.Parse(tb.env.endpoint); parseErr == nil && u.Host != "" {
+			opts = append(opts, WithOTLPURLPath(strings.TrimSuffix(u.Path, "/")+"/v1/traces"))
+		}
+
+		exp, err = newOTLPHTTPExporter(tb.ctx, tb.env.endpoint, opts)
+	}
+
+	if err != nil {
+		tb.addError(errors.ErrTraceExporterCreation, err)
+		return
+	}
+
+	tb.addExporter(exp)
+}
+
+// addEnvError records an invalid environment variable value.
+func (tb *TelemetryBuilder) addEnvError(name, value string, err error) {
+	tb.addError(errors.ErrInvalidEnvironment, fmt.Errorf("%s=%q: %w", name, value, err))
+}
+
+// samplerByName returns the sampler for an OTEL_TRACES_SAMPLER value. The ratio is only
+// used by the traceidratio samplers and must be between 0 and 1.
+func samplerByName(name string, ratio float64) (sdktrace.Sampler, error) {
+	if strings.HasSuffix(name, "traceidratio") && (ratio < 0 || ratio > 1) {
+		return nil, fmt.Errorf("%w: %g", errors.ErrInvalidSamplerArg, ratio)
+	}
+
+	switch name {
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errors.ErrUnknownSampler, name)
+	}
+}
+
+// parseSamplerArg parses OTEL_TRACES_SAMPLER_ARG, which defaults to a ratio of 1.
+func parseSamplerArg(arg string) (float64, error) {
+	if arg == "" {
+		return 1, nil
+	}
+
+	// An unparsable argument falls back to the default of 1, as the specification requires
+	ratio, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 1, fmt.Errorf("%w: %q", errors.ErrInvalidSamplerArg, arg)
+	}
+
+	return ratio, nil
+}
+
+// parseEnvList parses a comma separated list of URL encoded key=value pairs, as used by
+// OTEL_RESOURCE_ATTRIBUTES and OTEL_EXPORTER_OTLP_HEADERS.
+func parseEnvList(value string) (map[string]string, error) {
+	pairs := make(map[string]string)
+
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		key, val, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q", errors.ErrInvalidKeyValue, item)
+		}
+
+		decodedKey, err := url.PathUnescape(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errors.ErrInvalidKeyValue, item)
+		}
+
+		decodedVal, err := url.PathUnescape(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errors.ErrInvalidKeyValue, item)
+		}
+
+		pairs[decodedKey] = decodedVal
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,350 @@
+package traceflow
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// setOTELEnv clears every environment variable read by Init and then sets the given ones
+// for the duration of the test.
+func setOTELEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	for _, name := range []string{
+		envSDKDisabled, envServiceName, envResourceAttributes, envExporterEndpoint,
+		envExporterProtocol, envExporterHeaders, envTracesSampler, envTracesSamplerArg, envPropagators,
+	} {
+		t.Setenv(name, env[name])
+	}
+}
+
+// TestLoadEnv tests that each OTEL_* variable is parsed into the builder's environment config.
+func TestLoadEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		check func(t *testing.T, env envConfig)
+	}{
+		{
+			name: "empty",
+			env:  nil,
+			check: func(t *testing.T, env envConfig) {
+				assert.Equal(t, envConfig{}, env)
+			},
+		},
+		{
+			name: "service name and disabled",
+			env:  map[string]string{envServiceName: "checkout", envSDKDisabled: "TRUE"},
+			check: func(t *testing.T, env envConfig) {
+				assert.Equal(t, "checkout", env.serviceName)
+				assert.True(t, env.disabled)
+			},
+		},
+		{
+			name: "resource attributes",
+			env:  map[string]string{envResourceAttributes: "team=payments, region=eu%20west,"},
+			check: func(t *testing.T, env envConfig) {
+				assert.Equal(t, []attribute.KeyValue{
+					attribute.String("region", "eu west"),
+					attribute.String("team", "payments"),
+				}, env.resourceAttrs)
+			},
+		},
+		{
+			name: "exporter",
+			env: map[string]string{
+				envExporterEndpoint: "https://otel:4317",
+				envExporterProtocol: "grpc",
+				envExporterHeaders:  "authorization=Bearer%20token,x-tenant=a",
+			},
+			check: func(t *testing.T, env envConfig) {
+				assert.Equal(t, "https://otel:4317", env.endpoint)
+				assert.Equal(t, "grpc", env.protocol)
+				assert.Equal(t, map[string]string{"authorization": "Bearer token", "x-tenant": "a"}, env.headers)
+			},
+		},
+		{
+			name: "ratio sampler",
+			env:  map[string]string{envTracesSampler: "parentbased_traceidratio", envTracesSamplerArg: "0.25"},
+			check: func(t *testing.T, env envConfig) {
+				assert.Equal(t, sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.25)).Description(),
+					env.sampler.Description())
+			},
+		},
+		{
+			name: "always off sampler",
+			env:  map[string]string{envTracesSampler: "always_off"},
+			check: func(t *testing.T, env envConfig) {
+				assert.Equal(t, sdktrace.NeverSample().Description(), env.sampler.Description())
+			},
+		},
+		{
+			name: "propagators",
+			env:  map[string]string{envPropagators: "baggage, tracecontext"},
+			check: func(t *testing.T, env envConfig) {
+				assert.Equal(t, []propagation.TextMapPropagator{propagation.Baggage{}, propagation.TraceContext{}},
+					env.propagators)
+			},
+		},
+		{
+			name: "no propagators",
+			env:  map[string]string{envPropagators: "none"},
+			check: func(t *testing.T, env envConfig) {
+				assert.NotNil(t, env.propagators)
+				assert.Empty(t, env.propagators)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOTELEnv(t, tt.env)
+
+			builder := &TelemetryBuilder{}
+			builder.loadEnv()
+
+			assert.Empty(t, builder.errs)
+			tt.check(t, builder.env)
+		})
+	}
+}
+
+// TestLoadEnvInvalid tests that invalid values are reported as wrapped errors.
+func TestLoadEnvInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want error
+	}{
+		{"disabled", map[string]string{envSDKDisabled: "maybe"}, errors.ErrInvalidEnvironment},
+		{"resource attributes", map[string]string{envResourceAttributes: "team"}, errors.ErrInvalidKeyValue},
+		{"headers", map[string]string{envExporterHeaders: "=token"}, errors.ErrInvalidKeyValue},
+		{"protocol", map[string]string{envExporterProtocol: "http/json"}, errors.ErrUnsupportedProtocol},
+		{"sampler", map[string]string{envTracesSampler: "jaeger_remote"}, errors.ErrUnknownSampler},
+		{
+			"sampler argument",
+			map[string]string{envTracesSampler: "traceidratio", envTracesSamplerArg: "2"},
+			errors.ErrInvalidSamplerArg,
+		},
+		{"propagators", map[string]string{envPropagators: "tracecontext,xray"}, errors.ErrUnknownPropagator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOTELEnv(t, tt.env)
+
+			_, _, err := Init(context.Background(), "test-service", WithSilentLogger())
+
+			assert.ErrorIs(t, err, errors.ErrInvalidEnvironment)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+// TestInitEnvPrecedence tests that explicit arguments and options override the environment.
+func TestInitEnvPrecedence(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceName string
+		opts        []InitOption
+		wantService string
+		wantSpans   int
+	}{
+		{"environment", "", nil, "env-service", 0},
+		{"explicit sampler", "", []InitOption{WithSampler(sdktrace.AlwaysSample())}, "env-service", 1},
+		{
+			"explicit service name", "explicit-service",
+			[]InitOption{WithSampler(sdktrace.AlwaysSample())}, "explicit-service", 1,
+		},
+		{"explicit parent based", "", []InitOption{WithParentBased()}, "env-service", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOTELEnv(t, map[string]string{
+				envServiceName:        "env-service",
+				envTracesSampler:      "always_off",
+				envResourceAttributes: "service.name=ignored,team=payments",
+			})
+
+			exporter := tracetest.NewInMemoryExporter()
+			previous := otel.GetTracerProvider()
+
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+			ctx, shutdown, err := Init(context.Background(), tt.serviceName,
+				append([]InitOption{WithSilentLogger(), WithSpanExporter(exporter)}, tt.opts...)...)
+			require.NoError(t, err)
+
+			New(ctx, "test-service").Start("operation").End()
+
+			tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			require.True(t, ok)
+			require.NoError(t, tp.ForceFlush(ctx))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, tt.wantSpans)
+			require.NoError(t, shutdown(ctx))
+
+			if tt.wantSpans == 0 {
+				return
+			}
+
+			resource := spans[0].Resource.Set()
+
+			service, _ := resource.Value("service.name")
+			assert.Equal(t, tt.wantService, service.AsString())
+
+			team, _ := resource.Value("team")
+			assert.Equal(t, "payments", team.AsString())
+		})
+	}
+}
+
+// TestInitFromEnv tests that InitFromEnv names the service from OTEL_SERVICE_NAME.
+func TestInitFromEnv(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"named", map[string]string{envServiceName: "checkout"}, "checkout"},
+		{"resource attributes", map[string]string{envResourceAttributes: "service.name=billing"}, "billing"},
+		{
+			"service name over resource attributes",
+			map[string]string{envServiceName: "checkout", envResourceAttributes: "service.name=billing"},
+			"checkout",
+		},
+		{"unnamed", nil, defaultServiceName},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			setOTELEnv(t, tt.env)
+
+			exporter := tracetest.NewInMemoryExporter()
+			previous := otel.GetTracerProvider()
+
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+			ctx, shutdown, err := InitFromEnv(context.Background(), WithSilentLogger(), WithSpanExporter(exporter))
+			require.NoError(t, err)
+
+			New(ctx, "test-service").Start("operation").End()
+
+			tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			require.True(t, ok)
+			require.NoError(t, tp.ForceFlush(ctx))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			require.NoError(t, shutdown(ctx))
+
+			service, _ := spans[0].Resource.Set().Value("service.name")
+			assert.Equal(t, tt.want, service.AsString())
+		})
+	}
+}
+
+// TestInitInvalidSamplerArgFallback tests that an unparsable OTEL_TRACES_SAMPLER_ARG falls
+// back to the default ratio of 1 instead of dropping every trace.
+func TestInitInvalidSamplerArgFallback(t *testing.T) {
+	setOTELEnv(t, map[string]string{envTracesSampler: "traceidratio", envTracesSamplerArg: "half"})
+
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithLogger(log.New(io.Discard, "", 0)),
+		WithSpanExporter(exporter),
+		WithFallbackOnError(),
+	)
+	require.NoError(t, err)
+
+	for range 10 {
+		New(ctx, "test-service").Start("operation").End()
+	}
+
+	tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+	require.NoError(t, tp.ForceFlush(ctx))
+
+	assert.Len(t, exporter.GetSpans(), 10)
+	require.NoError(t, shutdown(ctx))
+}
+
+// TestInitSDKDisabled tests that OTEL_SDK_DISABLED leaves the global providers untouched.
+func TestInitSDKDisabled(t *testing.T) {
+	setOTELEnv(t, map[string]string{envSDKDisabled: "true"})
+
+	previous := otel.GetTracerProvider()
+	disabled := noop.NewTracerProvider()
+	otel.SetTracerProvider(disabled)
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, shutdown, err := Init(context.Background(), "test-service", WithSilentLogger())
+	require.NoError(t, err)
+
+	assert.NotNil(t, ctx)
+	assert.Equal(t, disabled, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(ctx))
+}
+
+// TestInitEnvOTLPExporter tests that OTEL_EXPORTER_OTLP_* configures an OTLP HTTP exporter.
+func TestInitEnvOTLPExporter(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		paths         []string
+		authorization string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		paths = append(paths, r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	setOTELEnv(t, map[string]string{
+		envExporterEndpoint: server.URL + "/collector",
+		envExporterProtocol: "http/protobuf",
+		envExporterHeaders:  "Authorization=Bearer%20secret",
+	})
+
+	previous := otel.GetTracerProvider()
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, shutdown, err := Init(context.Background(), "test-service", WithLogger(log.New(io.Discard, "", 0)))
+	require.NoError(t, err)
+
+	New(ctx, "test-service").Start("exported").End()
+	require.NoError(t, shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"/collector/v1/traces"}, paths)
+	assert.Equal(t, "Bearer secret", authorization)
+}
@@ -1,15 +1,39 @@
 package traceflow
 
 import (
+	"fmt"
 	"reflect"
+	"runtime/debug"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// PanicError is returned by EndWithRecoverError when a panic is recovered. It carries
+// the original panic value and the stack trace captured at the time of the panic.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is itself an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
 // AddException adds exception information to the trace.
 func (t *Trace) AddException(err error, stackTrace string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("exception.type", reflect.TypeOf(err).String()),
 		attribute.String("exception.message", err.Error()),
 		attribute.String("exception.stacktrace", stackTrace),
@@ -20,7 +44,7 @@ func (t *Trace) AddException(err error, stackTrace string) *Trace {
 
 // AddError adds error information to the trace.
 func (t *Trace) AddError(err error) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("error.type", reflect.TypeOf(err).String()),
 		attribute.String("error.message", err.Error()),
 	)
@@ -30,8 +54,96 @@ func (t *Trace) AddError(err error) *Trace {
 
 // RecordError records an error to the span and sets the span status to Error.
 func (t *Trace) RecordError(err error) {
-	if err != nil {
-		t.span.RecordError(err)
-		t.span.SetStatus(codes.Error, err.Error())
+	if err == nil {
+		return
+	}
+
+	if span := t.currentSpan(); span != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// Recover captures a panic in the traced function, records it on the span as an exception
+// event with the panic value, type, and full stack trace, marks the span as Error, ends the
+// span, and then re-panics with the original value. If there is no panic, Recover does nothing.
+//
+// Recover must be called directly with defer for the panic to be captured.
+//
+// Example usage:
+//
+//	trace := traceflow.New(ctx, "my-service").Start("process")
+//	defer trace.End()
+//	defer trace.Recover()
+func (t *Trace) Recover() {
+	if r := recover(); r != nil {
+		t.recordPanic(r, debug.Stack(), true)
+		t.End()
+
+		panic(r)
+	}
+}
+
+// EndWithRecover ends the span, first recording any in-flight panic in the same way as
+// Recover. The panic is re-raised after the span has ended.
+//
+// EndWithRecover must be called directly with defer for the panic to be captured.
+//
+// Example usage:
+//
+//	defer traceflow.New(ctx, "my-service").Start("process").EndWithRecover()
+func (t *Trace) EndWithRecover() {
+	if r := recover(); r != nil {
+		t.recordPanic(r, debug.Stack(), true)
+		t.End()
+
+		panic(r)
 	}
+
+	t.End()
+}
+
+// EndWithRecoverError ends the span, recording any in-flight panic in the same way as
+// Recover, but swallows the panic and stores it in err as a *PanicError instead of
+// re-panicking. If there is no panic, err is left untouched.
+//
+// EndWithRecoverError must be called directly with defer for the panic to be captured.
+//
+// Example usage:
+//
+//	func process(ctx context.Context) (err error) {
+//	    defer traceflow.New(ctx, "my-service").Start("process").EndWithRecoverError(&err)
+//	    // ...
+//	}
+func (t *Trace) EndWithRecoverError(err *error) {
+	if r := recover(); r != nil {
+		stack := debug.Stack()
+
+		t.recordPanic(r, stack, false)
+
+		if err != nil {
+			*err = &PanicError{Value: r, Stack: stack}
+		}
+	}
+
+	t.End()
+}
+
+// recordPanic records a recovered panic as an exception event and sets the span status to Error.
+// escaped reports whether the panic continues to propagate past the span.
+func (t *Trace) recordPanic(value any, stack []byte, escaped bool) {
+	span := t.currentSpan()
+	if span == nil {
+		return
+	}
+
+	message := fmt.Sprint(value)
+
+	span.AddEvent("exception", trace.WithAttributes(
+		attribute.String("exception.type", fmt.Sprintf("%T", value)),
+		attribute.String("exception.message", message),
+		attribute.String("exception.stacktrace", string(stack)),
+		attribute.Bool("exception.escaped", escaped),
+	))
+	span.SetStatus(codes.Error, "panic: "+message)
 }
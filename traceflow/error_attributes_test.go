@@ -3,10 +3,13 @@ package traceflow
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -105,3 +108,119 @@ func (m *mockSpan) SetStatus(code codes.Code, description string) {
 	m.statusCode = code
 	m.statusDescription = description
 }
+
+// TestRecover tests that Recover records the panic, ends the span, and re-panics.
+func TestRecover(t *testing.T) {
+	tr, exporter := newRecordedTrace(t)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected panic to be re-raised with 'boom', got %v", r)
+			}
+		}()
+
+		tr.Start("panicking")
+		defer tr.Recover()
+
+		panic("boom")
+	}()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 exported span, got %d", len(spans))
+	}
+
+	assertPanicRecorded(t, spans[0], "string", "boom", true)
+}
+
+// TestEndWithRecover tests that EndWithRecover ends the span with and without a panic.
+func TestEndWithRecover(t *testing.T) {
+	tr, exporter := newRecordedTrace(t)
+
+	func() {
+		defer tr.Start("no-panic").EndWithRecover()
+	}()
+
+	assert.Panics(t, func() {
+		defer tr.Child().Start("panicking").EndWithRecover()
+
+		panic(errors.New("exploded"))
+	})
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(spans))
+	}
+
+	if spans[0].Status.Code != codes.Unset || len(spans[0].Events) != 0 {
+		t.Errorf("Expected untouched span without panic, got status %v and %d events", spans[0].Status.Code, len(spans[0].Events))
+	}
+
+	assertPanicRecorded(t, spans[1], "*errors.errorString", "exploded", true)
+}
+
+// TestEndWithRecoverError tests that EndWithRecoverError swallows the panic and returns it.
+func TestEndWithRecoverError(t *testing.T) {
+	tr, exporter := newRecordedTrace(t)
+	cause := errors.New("exploded")
+
+	run := func() (err error) {
+		defer tr.Start("panicking").EndWithRecoverError(&err)
+
+		panic(cause)
+	}
+
+	err := run()
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected a *PanicError, got %v", err)
+	}
+
+	if !errors.Is(err, cause) {
+		t.Error("Expected PanicError to unwrap to the panic value")
+	}
+
+	if !strings.Contains(string(panicErr.Stack), "TestEndWithRecoverError") {
+		t.Error("Expected the stack trace to include the panicking function")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 exported span, got %d", len(spans))
+	}
+
+	assertPanicRecorded(t, spans[0], "*errors.errorString", "exploded", false)
+}
+
+// assertPanicRecorded checks that a span carries an exception event for a recovered panic.
+func assertPanicRecorded(t *testing.T, span tracetest.SpanStub, panicType, message string, escaped bool) {
+	t.Helper()
+
+	if span.Status.Code != codes.Error {
+		t.Errorf("Expected span status Error, got %v", span.Status.Code)
+	}
+
+	if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+		t.Fatalf("Expected a single exception event, got %v", span.Events)
+	}
+
+	attrs := attribute.NewSet(span.Events[0].Attributes...)
+
+	if v, _ := attrs.Value("exception.type"); v.AsString() != panicType {
+		t.Errorf("Expected exception.type %q, got %q", panicType, v.AsString())
+	}
+
+	if v, _ := attrs.Value("exception.message"); v.AsString() != message {
+		t.Errorf("Expected exception.message %q, got %q", message, v.AsString())
+	}
+
+	if v, _ := attrs.Value("exception.stacktrace"); !strings.Contains(v.AsString(), "runtime/debug.Stack") {
+		t.Errorf("Expected exception.stacktrace to contain a goroutine stack, got %q", v.AsString())
+	}
+
+	if v, _ := attrs.Value("exception.escaped"); v.AsBool() != escaped {
+		t.Errorf("Expected exception.escaped %v, got %v", escaped, v.AsBool())
+	}
+}
@@ -0,0 +1,120 @@
+package traceflow
+
+import (
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanFilter decides whether a finished span is sent to an exporter.
+type SpanFilter func(span sdktrace.ReadOnlySpan) bool
+
+// ExporterOption defines a functional option for configuring an exporter registered with
+// WithSpanExporter.
+type ExporterOption func(*spanExporter)
+
+// spanExporter is an exporter registered on the TelemetryBuilder. Every exporter gets its
+// own batch span processor, so a slow or failing backend does not hold up the others.
+type spanExporter struct {
+	exporter sdktrace.SpanExporter
+	filter   SpanFilter
+	buffer   *diskBuffer
+}
+
+// diskBuffer holds the disk buffer settings of a registered exporter.
+type diskBuffer struct {
+	dir  string
+	opts []BufferOption
+}
+
+// WithSpanFilter only sends spans for which filter returns true to the exporter.
+//
+// Example usage:
+//
+//	traceflow.WithSpanExporter(errorExporter, traceflow.WithSpanFilter(func(s sdktrace.ReadOnlySpan) bool {
+//	    return s.Status().Code == codes.Error
+//	}))
+func WithSpanFilter(filter SpanFilter) ExporterOption {
+	return func(e *spanExporter) {
+		e.filter = filter
+	}
+}
+
+// WithDiskBuffer spools batches the exporter fails to send to a bounded queue in dir and
+// replays them once it recovers. See NewBufferedExporter. Spans the buffer drops when it is
+// full are reported to the OpenTelemetry error handler; the Dropped and Pending counters are
+// only available on an exporter created with NewBufferedExporter.
+//
+// Example usage:
+//
+//	traceflow.WithSpanExporter(exporter, traceflow.WithDiskBuffer("/var/lib/my-service/spans",
+//	    traceflow.WithBufferMaxSize(256<<20),
+//	))
+func WithDiskBuffer(dir string, opts ...BufferOption) ExporterOption {
+	return func(e *spanExporter) {
+		e.buffer = &diskBuffer{dir: dir, opts: opts}
+	}
+}
+
+// addExporter registers an exporter on the builder.
+func (tb *TelemetryBuilder) addExporter(exporter sdktrace.SpanExporter, opts ...ExporterOption) {
+	registered := spanExporter{exporter: exporter}
+	for _, opt := range opts {
+		opt(&registered)
+	}
+
+	if registered.buffer != nil {
+		buffered, err := NewBufferedExporter(exporter, registered.buffer.dir, registered.buffer.opts...)
+		if err != nil {
+			tb.addError(errors.ErrDiskBufferCreation, err)
+			return
+		}
+
+		registered.exporter = buffered
+	}
+
+	tb.exporters = append(tb.exporters, registered)
+}
+
+// spanProcessor returns the batch span processor that feeds the exporter, applying its
+// filter and the builder's redaction rules.
+func (e spanExporter) spanProcessor(tb *TelemetryBuilder) sdktrace.SpanProcessor {
+	exporter := e.exporter
+	if len(tb.redactions) > 0 {
+		exporter = &redactingExporter{SpanExporter: exporter, rules: tb.redactions}
+	}
+
+	// Count the spans passed to and exported by the batch processor, so that shutdown can
+	// report those it lost
+	if tb.queue != nil {
+		exporter = &queueingExporter{SpanExporter: exporter, queue: tb.queue}
+	}
+
+	var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(
+		exporter,
+		append([]sdktrace.BatchSpanProcessorOption{sdktrace.WithBatchTimeout(tb.batchTimeout)},
+			tb.batchOptions...)...,
+	)
+
+	if tb.queue != nil {
+		processor = &queueingProcessor{SpanProcessor: processor, queue: tb.queue}
+	}
+
+	if e.filter != nil {
+		processor = &filteringProcessor{SpanProcessor: processor, filter: e.filter}
+	}
+
+	return processor
+}
+
+// filteringProcessor passes only the spans accepted by its filter to the wrapped processor.
+type filteringProcessor struct {
+	sdktrace.SpanProcessor
+	filter SpanFilter
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *filteringProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	if p.filter(span) {
+		p.SpanProcessor.OnEnd(span)
+	}
+}
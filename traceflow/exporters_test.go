@@ -0,0 +1,142 @@
+package traceflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestMultipleExporters tests that every registered exporter receives the spans.
+func TestMultipleExporters(t *testing.T) {
+	second := tracetest.NewInMemoryExporter()
+	spans := initRecording(t, WithSpanExporter(second))
+
+	startSpans(3)
+
+	assert.Len(t, spans(), 3)
+	assert.Len(t, second.GetSpans(), 3)
+}
+
+// TestSpanFilter tests that a filtered exporter only receives matching spans.
+func TestSpanFilter(t *testing.T) {
+	errorsOnly := tracetest.NewInMemoryExporter()
+	spans := initRecording(t, WithSpanExporter(errorsOnly, WithSpanFilter(func(s sdktrace.ReadOnlySpan) bool {
+		return s.Status().Code == codes.Error
+	})))
+
+	New(context.Background(), "test-service").Start("ok").End()
+
+	failed := New(context.Background(), "test-service").Start("failed")
+	failed.SetStatus(codes.Error, "boom")
+	failed.End()
+
+	assert.Len(t, spans(), 2)
+
+	if assert.Len(t, errorsOnly.GetSpans(), 1) {
+		assert.Equal(t, "test-service.failed", errorsOnly.GetSpans()[0].Name)
+	}
+}
+
+// failingExporter fails every export.
+type failingExporter struct {
+	tracetest.InMemoryExporter
+}
+
+var errExportFailed = errors.New("export failed")
+
+func (e *failingExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error {
+	return errExportFailed
+}
+
+// blockingExporter blocks every export until it is released.
+type blockingExporter struct {
+	tracetest.InMemoryExporter
+	release chan struct{}
+}
+
+func (e *blockingExporter) ExportSpans(ctx context.Context, _ []sdktrace.ReadOnlySpan) error {
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+	}
+
+	return nil
+}
+
+// TestExporterIsolation tests that a failing or stuck exporter does not stop the others
+// from receiving spans.
+func TestExporterIsolation(t *testing.T) {
+	healthy := tracetest.NewInMemoryExporter()
+	blocking := &blockingExporter{release: make(chan struct{})}
+	previous := otel.GetTracerProvider()
+	previousHandler := otel.GetErrorHandler()
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) {}))
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithBatchTimeout(10*time.Millisecond),
+		WithSpanExporter(&failingExporter{}),
+		WithSpanExporter(blocking),
+		WithSpanExporter(healthy),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		close(blocking.release)
+		shutdown(ctx)
+		otel.SetTracerProvider(previous)
+		otel.SetErrorHandler(previousHandler)
+	})
+
+	startSpans(2)
+
+	assert.Eventually(t, func() bool {
+		return len(healthy.GetSpans()) == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestOTLPAndFileExporters tests that an OTLP collector and a local file both receive spans.
+func TestOTLPAndFileExporters(t *testing.T) {
+	var received atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "traces.log")
+	previous := otel.GetTracerProvider()
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithOTLPHTTP(server.URL),
+		WithFileLogging(path),
+	)
+	require.NoError(t, err)
+
+	New(ctx, "test-service").Start("exported").End()
+	require.NoError(t, shutdown(ctx))
+
+	assert.Equal(t, int32(1), received.Load())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "test-service.exported")
+}
@@ -3,8 +3,8 @@ package traceflow
 import (
 	"context"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -43,6 +43,30 @@ import (
 //   - If no metadata is found in the context, or the trace context is missing, the method
 //     returns the original context unmodified.
 func ExtractGRPCContext(ctx context.Context) context.Context {
+	return defaultTelemetry.ExtractGRPCContext(ctx)
+}
+
+// ExtractGRPCContext extracts the trace context from the incoming gRPC metadata with the
+// Telemetry's propagator. See ExtractGRPCContext.
+func (tel *Telemetry) ExtractGRPCContext(ctx context.Context) context.Context {
+	return extractGRPCContext(ctx, tel.Propagator())
+}
+
+// withGRPCContext extracts the trace context from the incoming gRPC metadata of the trace's
+// context using the propagator of the trace's Telemetry.
+func withGRPCContext() Option {
+	return func(t *Trace) {
+		t.ctx = extractGRPCContext(t.ctx, t.telemetry.Propagator())
+
+		if span := trace.SpanFromContext(t.ctx); span.SpanContext().IsValid() {
+			t.parentSpanID = span.SpanContext().SpanID().String()
+		}
+	}
+}
+
+// extractGRPCContext extracts the trace context from the incoming gRPC metadata with the
+// given propagator.
+func extractGRPCContext(ctx context.Context, propagator propagation.TextMapPropagator) context.Context {
 	// Extract incoming metadata from the gRPC context
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -59,7 +83,6 @@ func ExtractGRPCContext(ctx context.Context) context.Context {
 	}
 
 	// Extract the trace context from the metadata map
-	propagator := otel.GetTextMapPropagator()
 	carrier := propagation.MapCarrier(mdMap)
 
 	newCtx := propagator.Extract(ctx, carrier)
@@ -0,0 +1,714 @@
+package traceflow
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError describes an invalid field in a configuration file loaded by InitFromFile.
+type ConfigError struct {
+	// File is the path of the configuration file.
+	File string
+
+	// Line is the line of the offending field, starting at 1.
+	Line int
+
+	// Field is the path of the offending field, such as "exporters[1].endpoint".
+	Field string
+
+	// Err describes what is wrong with the field.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s:%d: %s: %v", e.File, e.Line, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// fileConfig is the schema of a traceflow configuration file.
+type fileConfig struct {
+	ServiceName        string                `yaml:"service_name"`
+	ResourceAttributes map[string]string     `yaml:"resource_attributes"`
+	Exporters          []fileExporterConfig  `yaml:"exporters"`
+	Sampler            *fileSamplerConfig    `yaml:"sampler"`
+	Propagators        []string              `yaml:"propagators"`
+	Redaction          []fileRedactionConfig `yaml:"redaction"`
+	Batch              *fileBatchConfig      `yaml:"batch"`
+	TailSampling       *fileTailConfig       `yaml:"tail_sampling"`
+}
+
+// fileExporterConfig configures one exporter in a configuration file.
+type fileExporterConfig struct {
+	Type        string            `yaml:"type"`
+	Protocol    string            `yaml:"protocol"`
+	Endpoint    string            `yaml:"endpoint"`
+	URLPath     string            `yaml:"url_path"`
+	Path        string            `yaml:"path"`
+	MaxSize     int64             `yaml:"max_size"`
+	MaxAge      time.Duration     `yaml:"max_age"`
+	MaxBackups  int               `yaml:"max_backups"`
+	Compress    bool              `yaml:"compress"`
+	Headers     map[string]string `yaml:"headers"`
+	Compression string            `yaml:"compression"`
+	Timeout     time.Duration     `yaml:"timeout"`
+	TLS         *fileTLSConfig    `yaml:"tls"`
+	Retry       *fileRetryConfig  `yaml:"retry"`
+	Buffer      *fileBufferConfig `yaml:"buffer"`
+}
+
+// fileTLSConfig configures TLS for an OTLP exporter in a configuration file.
+type fileTLSConfig struct {
+	CAFile   string `yaml:"ca_file"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// fileRetryConfig configures the retry backoff of an OTLP exporter in a configuration file.
+type fileRetryConfig struct {
+	InitialInterval time.Duration `yaml:"initial_interval"`
+	MaxInterval     time.Duration `yaml:"max_interval"`
+	MaxElapsedTime  time.Duration `yaml:"max_elapsed_time"`
+}
+
+// fileBufferConfig configures the disk buffer of an OTLP exporter in a configuration file.
+type fileBufferConfig struct {
+	Dir     string `yaml:"dir"`
+	MaxSize int64  `yaml:"max_size"`
+}
+
+// fileSamplerConfig configures the sampler in a configuration file.
+type fileSamplerConfig struct {
+	Type string   `yaml:"type"`
+	Arg  *float64 `yaml:"arg"`
+}
+
+// fileRedactionConfig configures a redaction rule in a configuration file.
+type fileRedactionConfig struct {
+	Key         string `yaml:"key"`
+	Replacement string `yaml:"replacement"`
+	Remove      bool   `yaml:"remove"`
+}
+
+// fileBatchConfig configures the batch span processor in a configuration file.
+type fileBatchConfig struct {
+	Timeout            time.Duration `yaml:"timeout"`
+	ExportTimeout      time.Duration `yaml:"export_timeout"`
+	MaxQueueSize       int           `yaml:"max_queue_size"`
+	MaxExportBatchSize int           `yaml:"max_export_batch_size"`
+}
+
+// fileTailConfig configures tail sampling in a configuration file.
+type fileTailConfig struct {
+	DecisionWait     time.Duration     `yaml:"decision_wait"`
+	MaxTraces        int               `yaml:"max_traces"`
+	MaxSpansPerTrace int               `yaml:"max_spans_per_trace"`
+	Errors           bool              `yaml:"errors"`
+	Latency          time.Duration     `yaml:"latency"`
+	Attributes       map[string]string `yaml:"attributes"`
+	Ratio            *float64          `yaml:"ratio"`
+}
+
+// InitFromFile initializes OpenTelemetry from a YAML or JSON configuration file describing
+// the service name, resource attributes, exporters, sampler, propagators, attribute
+// redaction rules, batch settings and tail sampling. Values may reference environment
+// variables as ${NAME} or ${NAME:-default}; use $$ for a literal dollar sign. Options
+// passed to InitFromFile take precedence over the file.
+//
+// Invalid files are reported with a *ConfigError per problem, giving the line and field.
+//
+// Example traceflow.yaml:
+//
+//	service_name: checkout
+//	resource_attributes:
+//	  deployment.environment: ${DEPLOY_ENV:-dev}
+//	exporters:
+//	  - type: file
+//	    path: /var/log/traces.jsonl
+//	    max_size: 104857600
+//	    max_backups: 5
+//	    compress: true
+//	  - type: otlp
+//	    protocol: grpc
+//	    endpoint: otel:4317
+//	    headers:
+//	      authorization: Bearer ${OTEL_TOKEN}
+//	    buffer:
+//	      dir: /var/lib/checkout/spans
+//	sampler:
+//	  type: parentbased_traceidratio
+//	  arg: 0.1
+//	propagators: [tracecontext, baggage]
+//	redaction:
+//	  - key: http.header.Authorization
+//	batch:
+//	  timeout: 5s
+//	  max_queue_size: 4096
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.InitFromFile(ctx, "traceflow.yaml")
+//	if err != nil {
+//	    log.Fatalf("Failed to initialize OpenTelemetry: %v", err)
+//	}
+//	defer shutdown(ctx)
+func InitFromFile(ctx context.Context, path string, opts ...InitOption) (context.Context, func(context.Context) error, error) {
+	cfg, err := loadFileConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return Init(ctx, cfg.ServiceName, append(cfg.options(), opts...)...)
+}
+
+// loadFileConfig reads, interpolates and validates a configuration file.
+func loadFileConfig(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrInvalidConfig, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errors.ErrInvalidConfig, path, err)
+	}
+
+	cfg := &fileConfig{}
+
+	// An empty file is a valid, empty configuration
+	if len(doc.Content) == 0 {
+		return cfg, nil
+	}
+
+	root := doc.Content[0]
+	lines := make(map[string]int)
+
+	errs := prepareConfigNode(root, reflect.TypeOf(cfg).Elem(), "", lines)
+	if len(errs) == 0 {
+		if err := root.Decode(cfg); err != nil {
+			errs = configTypeErrors(err, lines)
+		} else {
+			errs = cfg.validate(lines)
+		}
+	}
+
+	if len(errs) == 0 {
+		return cfg, nil
+	}
+
+	joined := make([]error, len(errs))
+	for i, err := range errs {
+		err.File = path
+		joined[i] = err
+	}
+
+	return nil, fmt.Errorf("%w: %w", errors.ErrInvalidConfig, stderrors.Join(joined...))
+}
+
+// configTypeErrors converts the "line N: cannot unmarshal ..." messages of a YAML decoding
+// error into ConfigErrors naming the field on that line.
+func configTypeErrors(err error, lines map[string]int) []*ConfigError {
+	var typeErr *yaml.TypeError
+	if !stderrors.As(err, &typeErr) {
+		return []*ConfigError{{Err: fmt.Errorf("%w: %w", errors.ErrInvalidValue, err)}}
+	}
+
+	errs := make([]*ConfigError, 0, len(typeErr.Errors))
+
+	for _, msg := range typeErr.Errors {
+		var line int
+
+		prefix, detail, _ := strings.Cut(msg, ": ")
+		if _, scanErr := fmt.Sscanf(prefix, "line %d", &line); scanErr != nil {
+			detail = msg
+		}
+
+		// Pick the most specific field that starts on the line
+		field := ""
+
+		for path, fieldLine := range lines {
+			if fieldLine == line && (len(path) > len(field) || len(path) == len(field) && path > field) {
+				field = path
+			}
+		}
+
+		errs = append(errs, &ConfigError{
+			Line:  line,
+			Field: field,
+			Err:   fmt.Errorf("%w: %s", errors.ErrInvalidValue, detail),
+		})
+	}
+
+	return errs
+}
+
+// prepareConfigNode walks a parsed YAML node against the configuration type. It expands
+// environment variables in scalar values, reports fields that the type does not define,
+// and records the line of every field in lines, keyed by field path.
+func prepareConfigNode(node *yaml.Node, typ reflect.Type, path string, lines map[string]int) []*ConfigError {
+	lines[path] = node.Line
+
+	var errs []*ConfigError
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return prepareConfigNode(node, typ.Elem(), path, lines)
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		fields := make(map[string]reflect.Type, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			fields[typ.Field(i).Tag.Get("yaml")] = typ.Field(i).Type
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldPath := joinConfigPath(path, key.Value)
+
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				errs = append(errs, &ConfigError{Line: key.Line, Field: fieldPath, Err: errors.ErrUnknownField})
+				continue
+			}
+
+			errs = append(errs, prepareConfigNode(value, fieldType, fieldPath, lines)...)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			errs = append(errs, prepareConfigNode(value, typ.Elem(), joinConfigPath(path, key.Value), lines)...)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+
+		for i, item := range node.Content {
+			errs = append(errs, prepareConfigNode(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), lines)...)
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			return nil
+		}
+
+		value, err := expandConfigValue(node.Value)
+		if err != nil {
+			return []*ConfigError{{Line: node.Line, Field: path, Err: err}}
+		}
+
+		if value != node.Value {
+			node.Value = value
+
+			// Let plain scalars be resolved again, so "${RATIO}" can decode as a number
+			if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				node.Tag = ""
+			}
+		}
+	}
+
+	return errs
+}
+
+// configVariable matches an escaped dollar sign or a ${NAME} / ${NAME:-default} reference.
+var configVariable = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// expandConfigValue replaces environment variable references in a configuration value.
+func expandConfigValue(value string) (string, error) {
+	var missing []string
+
+	expanded := configVariable.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+
+		groups := configVariable.FindStringSubmatch(match)
+		name, fallback := groups[1], groups[2]
+
+		if env, ok := os.LookupEnv(name); ok && (env != "" || fallback == "") {
+			return env
+		}
+
+		if fallback != "" {
+			return strings.TrimPrefix(fallback, ":-")
+		}
+
+		missing = append(missing, name)
+
+		return ""
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", errors.ErrUnsetVariable, strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
+
+// joinConfigPath appends a field name to a field path.
+func joinConfigPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
+
+// configLine returns the line of the field at path, falling back to its closest parent.
+func configLine(lines map[string]int, path string) int {
+	for {
+		if line, ok := lines[path]; ok {
+			return line
+		}
+
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return lines[""]
+		}
+
+		path = path[:i]
+	}
+}
+
+// validate checks the decoded configuration for invalid or missing values.
+func (c *fileConfig) validate(lines map[string]int) []*ConfigError {
+	var errs []*ConfigError
+
+	invalid := func(field string, err error) {
+		errs = append(errs, &ConfigError{Line: configLine(lines, field), Field: field, Err: err})
+	}
+
+	for i, exp := range c.Exporters {
+		path := fmt.Sprintf("exporters[%d]", i)
+
+		switch exp.Type {
+		case "otlp":
+			if exp.Endpoint == "" {
+				invalid(path+".endpoint", errors.ErrMissingField)
+			}
+
+			switch exp.Protocol {
+			case "", "grpc", "http/protobuf":
+			default:
+				invalid(path+".protocol", fmt.Errorf("%w: %q", errors.ErrUnsupportedProtocol, exp.Protocol))
+			}
+
+			switch exp.Compression {
+			case "", "none", "gzip":
+			default:
+				invalid(path+".compression", fmt.Errorf("%w: %q", errors.ErrInvalidValue, exp.Compression))
+			}
+
+			if exp.TLS != nil && (exp.TLS.CertFile == "") != (exp.TLS.KeyFile == "") {
+				invalid(path+".tls", fmt.Errorf("%w: cert_file and key_file must be set together", errors.ErrInvalidValue))
+			}
+
+			if exp.Buffer != nil && exp.Buffer.Dir == "" {
+				invalid(path+".buffer.dir", errors.ErrMissingField)
+			}
+
+			if exp.Buffer != nil && exp.Buffer.MaxSize < 0 {
+				invalid(path+".buffer.max_size", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+			}
+		case "file":
+			if exp.Path == "" {
+				invalid(path+".path", errors.ErrMissingField)
+			}
+
+			if exp.MaxSize < 0 {
+				invalid(path+".max_size", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+			}
+
+			if exp.MaxBackups < 0 {
+				invalid(path+".max_backups", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+			}
+		case "stdout":
+		case "":
+			invalid(path+".type", errors.ErrMissingField)
+		default:
+			invalid(path+".type", fmt.Errorf("%w: %q", errors.ErrUnknownExporter, exp.Type))
+		}
+	}
+
+	if c.Sampler != nil {
+		switch {
+		case c.Sampler.Type == "":
+			invalid("sampler.type", errors.ErrMissingField)
+		case c.Sampler.Type == "rate_limited":
+			if c.Sampler.Arg == nil || *c.Sampler.Arg < 0 {
+				invalid("sampler.arg", errors.ErrInvalidSamplerArg)
+			}
+		default:
+			if _, err := samplerByName(c.Sampler.Type, c.Sampler.ratio()); err != nil {
+				field := "sampler.type"
+				if stderrors.Is(err, errors.ErrInvalidSamplerArg) {
+					field = "sampler.arg"
+				}
+
+				invalid(field, err)
+			}
+		}
+	}
+
+	for i, name := range c.Propagators {
+		if _, err := propagatorByName(name); err != nil {
+			invalid(fmt.Sprintf("propagators[%d]", i), err)
+		}
+	}
+
+	for i, rule := range c.Redaction {
+		path := fmt.Sprintf("redaction[%d]", i)
+
+		if rule.Key == "" {
+			invalid(path+".key", errors.ErrMissingField)
+		}
+
+		if rule.Remove && rule.Replacement != "" {
+			invalid(path+".replacement", fmt.Errorf("%w: cannot be combined with remove", errors.ErrInvalidValue))
+		}
+	}
+
+	if c.Batch != nil {
+		if c.Batch.MaxQueueSize < 0 {
+			invalid("batch.max_queue_size", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+		}
+
+		if c.Batch.MaxExportBatchSize < 0 {
+			invalid("batch.max_export_batch_size", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+		}
+
+		if c.Batch.MaxQueueSize > 0 && c.Batch.MaxExportBatchSize > c.Batch.MaxQueueSize {
+			invalid("batch.max_export_batch_size",
+				fmt.Errorf("%w: must not exceed max_queue_size", errors.ErrInvalidValue))
+		}
+	}
+
+	if c.TailSampling != nil {
+		if c.TailSampling.DecisionWait < 0 {
+			invalid("tail_sampling.decision_wait", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+		}
+
+		if c.TailSampling.MaxTraces < 0 {
+			invalid("tail_sampling.max_traces", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+		}
+
+		if c.TailSampling.MaxSpansPerTrace < 0 {
+			invalid("tail_sampling.max_spans_per_trace", fmt.Errorf("%w: must not be negative", errors.ErrInvalidValue))
+		}
+
+		if c.TailSampling.Ratio != nil && (*c.TailSampling.Ratio < 0 || *c.TailSampling.Ratio > 1) {
+			invalid("tail_sampling.ratio", fmt.Errorf("%w: must be between 0 and 1", errors.ErrInvalidValue))
+		}
+
+		if !c.TailSampling.Errors && c.TailSampling.Latency <= 0 && len(c.TailSampling.Attributes) == 0 &&
+			c.TailSampling.Ratio == nil {
+			invalid("tail_sampling", fmt.Errorf("%w: must set errors, latency, attributes or ratio", errors.ErrMissingField))
+		}
+	}
+
+	return errs
+}
+
+// ratio returns the sampler argument as a ratio, defaulting to 1.
+func (c *fileSamplerConfig) ratio() float64 {
+	if c.Arg == nil {
+		return 1
+	}
+
+	return *c.Arg
+}
+
+// options converts a validated configuration into InitOptions.
+func (c *fileConfig) options() []InitOption {
+	var opts []InitOption
+
+	if len(c.ResourceAttributes) > 0 {
+		attrs := make([]attribute.KeyValue, 0, len(c.ResourceAttributes))
+		for _, key := range slices.Sorted(maps.Keys(c.ResourceAttributes)) {
+			attrs = append(attrs, attribute.String(key, c.ResourceAttributes[key]))
+		}
+
+		opts = append(opts, WithResourceAttributes(attrs...))
+	}
+
+	for _, exp := range c.Exporters {
+		opts = append(opts, exp.option())
+	}
+
+	if c.Sampler != nil {
+		if c.Sampler.Type == "rate_limited" {
+			opts = append(opts, WithRateLimitedSampler(*c.Sampler.Arg))
+		} else {
+			sampler, _ := samplerByName(c.Sampler.Type, c.Sampler.ratio())
+			opts = append(opts, WithSampler(sampler))
+		}
+	}
+
+	if c.Propagators != nil {
+		propagators, _ := propagatorsByName(c.Propagators...)
+
+		opts = append(opts, func(tb *TelemetryBuilder) {
+			tb.propagators = propagators
+		})
+	}
+
+	if len(c.Redaction) > 0 {
+		rules := make([]RedactionRule, len(c.Redaction))
+		for i, rule := range c.Redaction {
+			rules[i] = RedactionRule(rule)
+		}
+
+		opts = append(opts, WithRedaction(rules...))
+	}
+
+	if c.Batch != nil {
+		opts = append(opts, c.Batch.option())
+	}
+
+	if c.TailSampling != nil {
+		opts = append(opts, c.TailSampling.option())
+	}
+
+	return opts
+}
+
+// option returns the InitOption that enables the configured tail sampling.
+func (c *fileTailConfig) option() InitOption {
+	opts := []TailSamplingOption{
+		WithTailDecisionWait(c.DecisionWait),
+		WithTailMaxTraces(c.MaxTraces),
+		WithTailMaxSpansPerTrace(c.MaxSpansPerTrace),
+	}
+
+	if c.Errors {
+		opts = append(opts, TailSampleErrors())
+	}
+
+	if c.Latency > 0 {
+		opts = append(opts, TailSampleLatency(c.Latency))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(c.Attributes)) {
+		opts = append(opts, TailSampleAttribute(attribute.String(key, c.Attributes[key])))
+	}
+
+	if c.Ratio != nil {
+		opts = append(opts, TailSampleRatio(*c.Ratio))
+	}
+
+	return WithTailSampling(opts...)
+}
+
+// option returns the InitOption that creates the configured exporter.
+func (c *fileExporterConfig) option() InitOption {
+	switch c.Type {
+	case "file":
+		opts := []FileOption{WithFileMaxSize(c.MaxSize), WithFileMaxAge(c.MaxAge), WithFileMaxBackups(c.MaxBackups)}
+		if c.Compress {
+			opts = append(opts, WithFileCompression())
+		}
+
+		return WithFileLogging(c.Path, opts...)
+	case "stdout":
+		return func(tb *TelemetryBuilder) {
+			exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+			if err != nil {
+				tb.addError(errors.ErrStdOutExporter, err)
+				return
+			}
+
+			tb.addExporter(exporter)
+		}
+	}
+
+	var opts []OTLPOption
+
+	if len(c.Headers) > 0 {
+		opts = append(opts, WithOTLPHeaders(c.Headers))
+	}
+
+	if c.Compression == "gzip" {
+		opts = append(opts, WithOTLPCompression())
+	}
+
+	if c.Timeout > 0 {
+		opts = append(opts, WithOTLPTimeout(c.Timeout))
+	}
+
+	if c.TLS != nil {
+		if c.TLS.CAFile != "" {
+			opts = append(opts, WithOTLPCACertificate(c.TLS.CAFile))
+		}
+
+		if c.TLS.CertFile != "" {
+			opts = append(opts, WithOTLPClientCertificate(c.TLS.CertFile, c.TLS.KeyFile))
+		}
+	}
+
+	if c.Retry != nil {
+		opts = append(opts, WithOTLPRetry(c.Retry.InitialInterval, c.Retry.MaxInterval, c.Retry.MaxElapsedTime))
+	}
+
+	if c.Buffer != nil {
+		var bufferOpts []BufferOption
+		if c.Buffer.MaxSize > 0 {
+			bufferOpts = append(bufferOpts, WithBufferMaxSize(c.Buffer.MaxSize))
+		}
+
+		opts = append(opts, WithOTLPDiskBuffer(c.Buffer.Dir, bufferOpts...))
+	}
+
+	if c.Protocol == "grpc" {
+		return WithOLTP(c.Endpoint, opts...)
+	}
+
+	if c.URLPath != "" {
+		opts = append(opts, WithOTLPURLPath(c.URLPath))
+	}
+
+	return WithOTLPHTTP(c.Endpoint, opts...)
+}
+
+// option returns the InitOption that applies the batch settings.
+func (c *fileBatchConfig) option() InitOption {
+	return func(tb *TelemetryBuilder) {
+		if c.Timeout > 0 {
+			tb.batchTimeout = c.Timeout
+		}
+
+		if c.ExportTimeout > 0 {
+			tb.batchOptions = append(tb.batchOptions, sdktrace.WithExportTimeout(c.ExportTimeout))
+		}
+
+		if c.MaxQueueSize > 0 {
+			tb.batchOptions = append(tb.batchOptions, sdktrace.WithMaxQueueSize(c.MaxQueueSize))
+		}
+
+		if c.MaxExportBatchSize > 0 {
+			tb.batchOptions = append(tb.batchOptions, sdktrace.WithMaxExportBatchSize(c.MaxExportBatchSize))
+		}
+	}
+}
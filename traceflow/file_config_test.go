@@ -0,0 +1,326 @@
+package traceflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// writeConfigFile writes a configuration file into a temporary directory and returns its path.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+// TestLoadFileConfig tests that YAML and JSON files decode into the same configuration.
+func TestLoadFileConfig(t *testing.T) {
+	t.Setenv("TEST_OTEL_TOKEN", "secret")
+	t.Setenv("TEST_OTEL_RATIO", "0.5")
+
+	want := &fileConfig{
+		ServiceName:        "checkout",
+		ResourceAttributes: map[string]string{"deployment.environment": "dev", "team": "payments"},
+		Exporters: []fileExporterConfig{{
+			Type:        "otlp",
+			Protocol:    "grpc",
+			Endpoint:    "otel:4317",
+			Headers:     map[string]string{"authorization": "Bearer secret"},
+			Compression: "gzip",
+			Timeout:     10 * time.Second,
+			Retry:       &fileRetryConfig{InitialInterval: time.Second, MaxInterval: 5 * time.Second},
+		}},
+		Sampler:     &fileSamplerConfig{Type: "traceidratio", Arg: ptr(0.5)},
+		Propagators: []string{"tracecontext", "baggage"},
+		Redaction: []fileRedactionConfig{
+			{Key: "http.header.Authorization"},
+			{Key: "user.*", Replacement: "$hidden"},
+		},
+		Batch: &fileBatchConfig{Timeout: 2 * time.Second, MaxQueueSize: 4096, MaxExportBatchSize: 512},
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "traceflow.yaml",
+			content: `
+service_name: checkout
+resource_attributes:
+  team: payments
+  deployment.environment: ${TEST_OTEL_UNSET_ENV:-dev}
+exporters:
+  - type: otlp
+    protocol: grpc
+    endpoint: otel:4317
+    headers:
+      authorization: Bearer ${TEST_OTEL_TOKEN}
+    compression: gzip
+    timeout: 10s
+    retry:
+      initial_interval: 1s
+      max_interval: 5s
+sampler:
+  type: traceidratio
+  arg: ${TEST_OTEL_RATIO}
+propagators: [tracecontext, baggage]
+redaction:
+  - key: http.header.Authorization
+  - key: user.*
+    replacement: $$hidden
+batch:
+  timeout: 2s
+  max_queue_size: 4096
+  max_export_batch_size: 512
+`,
+		},
+		{
+			name: "json",
+			file: "traceflow.json",
+			content: `{
+  "service_name": "checkout",
+  "resource_attributes": {"team": "payments", "deployment.environment": "${TEST_OTEL_UNSET_ENV:-dev}"},
+  "exporters": [{
+    "type": "otlp",
+    "protocol": "grpc",
+    "endpoint": "otel:4317",
+    "headers": {"authorization": "Bearer ${TEST_OTEL_TOKEN}"},
+    "compression": "gzip",
+    "timeout": "10s",
+    "retry": {"initial_interval": "1s", "max_interval": "5s"}
+  }],
+  "sampler": {"type": "traceidratio", "arg": 0.5},
+  "propagators": ["tracecontext", "baggage"],
+  "redaction": [
+    {"key": "http.header.Authorization"},
+    {"key": "user.*", "replacement": "$$hidden"}
+  ],
+  "batch": {"timeout": "2s", "max_queue_size": 4096, "max_export_batch_size": 512}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadFileConfig(writeConfigFile(t, tt.file, tt.content))
+			require.NoError(t, err)
+
+			assert.Equal(t, want, cfg)
+		})
+	}
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// TestLoadFileConfigErrors tests that invalid files report the line and field of each problem.
+func TestLoadFileConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		field   string
+		want    error
+	}{
+		{
+			name:    "unknown field",
+			content: "service_name: checkout\nexporters:\n  - type: otlp\n    endpont: otel:4317\n",
+			line:    4,
+			field:   "exporters[0].endpont",
+			want:    errors.ErrUnknownField,
+		},
+		{
+			name:    "missing endpoint",
+			content: "exporters:\n  - type: stdout\n  - type: otlp\n    protocol: grpc\n",
+			line:    3,
+			field:   "exporters[1].endpoint",
+			want:    errors.ErrMissingField,
+		},
+		{
+			name:    "unknown exporter",
+			content: "exporters:\n  - type: zipkin\n",
+			line:    2,
+			field:   "exporters[0].type",
+			want:    errors.ErrUnknownExporter,
+		},
+		{
+			name:    "unsupported protocol",
+			content: "exporters:\n  - type: otlp\n    endpoint: otel:4317\n    protocol: http/json\n",
+			line:    4,
+			field:   "exporters[0].protocol",
+			want:    errors.ErrUnsupportedProtocol,
+		},
+		{
+			name:    "bad duration",
+			content: "batch:\n  timeout: soon\n",
+			line:    2,
+			field:   "batch.timeout",
+			want:    errors.ErrInvalidValue,
+		},
+		{
+			name:    "unknown sampler",
+			content: "sampler:\n  type: sometimes\n",
+			line:    2,
+			field:   "sampler.type",
+			want:    errors.ErrUnknownSampler,
+		},
+		{
+			name:    "sampler ratio out of range",
+			content: "sampler:\n  type: traceidratio\n  arg: 1.5\n",
+			line:    3,
+			field:   "sampler.arg",
+			want:    errors.ErrInvalidSamplerArg,
+		},
+		{
+			name:    "unknown propagator",
+			content: "propagators:\n  - tracecontext\n  - xray\n",
+			line:    3,
+			field:   "propagators[1]",
+			want:    errors.ErrUnknownPropagator,
+		},
+		{
+			name:    "redaction without key",
+			content: "redaction:\n  - remove: true\n",
+			line:    2,
+			field:   "redaction[0].key",
+			want:    errors.ErrMissingField,
+		},
+		{
+			name:    "batch size",
+			content: "batch:\n  max_queue_size: 10\n  max_export_batch_size: 20\n",
+			line:    3,
+			field:   "batch.max_export_batch_size",
+			want:    errors.ErrInvalidValue,
+		},
+		{
+			name:    "buffer without dir",
+			content: "exporters:\n  - type: otlp\n    endpoint: otel:4317\n    buffer:\n      max_size: 1024\n",
+			line:    5,
+			field:   "exporters[0].buffer.dir",
+			want:    errors.ErrMissingField,
+		},
+		{
+			name:    "tail sampling ratio",
+			content: "tail_sampling:\n  errors: true\n  ratio: 2\n",
+			line:    3,
+			field:   "tail_sampling.ratio",
+			want:    errors.ErrInvalidValue,
+		},
+		{
+			name:    "tail sampling without policy",
+			content: "tail_sampling:\n  decision_wait: 5s\n",
+			line:    2,
+			field:   "tail_sampling",
+			want:    errors.ErrMissingField,
+		},
+		{
+			name:    "unset variable",
+			content: "service_name: checkout\nexporters:\n  - type: otlp\n    endpoint: ${TEST_OTEL_UNSET_ENDPOINT}\n",
+			line:    4,
+			field:   "exporters[0].endpoint",
+			want:    errors.ErrUnsetVariable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, "traceflow.yaml", tt.content)
+
+			_, err := loadFileConfig(path)
+			require.ErrorIs(t, err, errors.ErrInvalidConfig)
+			assert.ErrorIs(t, err, tt.want)
+
+			var configErr *ConfigError
+			require.ErrorAs(t, err, &configErr)
+
+			assert.Equal(t, path, configErr.File)
+			assert.Equal(t, tt.line, configErr.Line)
+			assert.Equal(t, tt.field, configErr.Field)
+		})
+	}
+}
+
+// TestLoadFileConfigSyntaxError tests that malformed files are rejected.
+func TestLoadFileConfigSyntaxError(t *testing.T) {
+	_, err := loadFileConfig(writeConfigFile(t, "traceflow.yaml", "exporters: [\n"))
+	assert.ErrorIs(t, err, errors.ErrInvalidConfig)
+
+	_, err = loadFileConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, errors.ErrInvalidConfig)
+}
+
+// TestInitFromFile tests that the file's settings are applied to the tracer provider.
+func TestInitFromFile(t *testing.T) {
+	setOTELEnv(t, nil)
+
+	path := writeConfigFile(t, "traceflow.yaml", `
+service_name: checkout
+resource_attributes:
+  team: payments
+sampler:
+  type: always_on
+propagators: [baggage]
+redaction:
+  - key: user.email
+    remove: true
+  - key: http.header.*
+batch:
+  timeout: 10ms
+`)
+
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	ctx, shutdown, err := InitFromFile(context.Background(), path, WithSilentLogger(), WithSpanExporter(exporter))
+	require.NoError(t, err)
+
+	New(ctx, "checkout").Start("operation").
+		AddAttribute(AddString("user.email", "jane@example.com"), AddString("http.header.Cookie", "id=1")).
+		End()
+
+	tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+	require.NoError(t, tp.ForceFlush(ctx))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.NoError(t, shutdown(ctx))
+
+	service, _ := spans[0].Resource.Set().Value("service.name")
+	assert.Equal(t, "checkout", service.AsString())
+
+	team, _ := spans[0].Resource.Set().Value("team")
+	assert.Equal(t, "payments", team.AsString())
+
+	_, found := findAttribute(spans[0], "user.email")
+	assert.False(t, found, "Expected user.email to be removed")
+
+	cookie, _ := findAttribute(spans[0], "http.header.Cookie")
+	assert.Equal(t, defaultRedactionReplacement, cookie.AsString())
+
+	assert.Equal(t, propagation.NewCompositeTextMapPropagator(propagation.Baggage{}), otel.GetTextMapPropagator())
+}
@@ -0,0 +1,388 @@
+package traceflow
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// backupTimeFormat names rotated files so that they sort in the order they were rotated.
+const backupTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// FileOption defines a functional option for configuring the file exporter created by
+// NewFileExporter and WithFileLogging.
+type FileOption func(*fileRotation)
+
+// fileRotation holds the rotation settings of a FileExporter.
+type fileRotation struct {
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+}
+
+// WithFileMaxSize rotates the file before a write would grow it beyond maxBytes.
+func WithFileMaxSize(maxBytes int64) FileOption {
+	return func(r *fileRotation) {
+		r.maxSize = maxBytes
+	}
+}
+
+// WithFileMaxAge rotates the file once it has been written to for longer than maxAge.
+func WithFileMaxAge(maxAge time.Duration) FileOption {
+	return func(r *fileRotation) {
+		r.maxAge = maxAge
+	}
+}
+
+// WithFileMaxBackups keeps at most n rotated files, deleting the oldest ones. By default
+// every rotated file is kept.
+func WithFileMaxBackups(n int) FileOption {
+	return func(r *fileRotation) {
+		r.maxBackups = n
+	}
+}
+
+// WithFileCompression gzips rotated files.
+func WithFileCompression() FileOption {
+	return func(r *fileRotation) {
+		r.compress = true
+	}
+}
+
+// FileExporter is a span exporter that writes one compact JSON object per span to a file,
+// rotating it by size and age. Rotated files are renamed with a timestamp, such as
+// "traces-2024-01-02T15-04-05.000000000.log", and optionally gzipped.
+//
+// Rotated files are compressed and pruned in the background. Failures to rotate, compress
+// or prune are reported through otel.Handle and do not stop the exporter.
+type FileExporter struct {
+	mu       sync.Mutex
+	path     string
+	rotation fileRotation
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	rotated  time.Time
+	shutdown bool
+	now      func() time.Time
+
+	// backupMu serializes the compression and pruning of rotated files
+	backupMu sync.Mutex
+	backupWG sync.WaitGroup
+}
+
+// NewFileExporter creates a FileExporter that appends spans to the file at path.
+//
+// Example usage:
+//
+//	exporter, err := traceflow.NewFileExporter("/var/log/traces.jsonl",
+//	    traceflow.WithFileMaxSize(100<<20),
+//	    traceflow.WithFileMaxBackups(5),
+//	    traceflow.WithFileCompression(),
+//	)
+func NewFileExporter(path string, opts ...FileOption) (*FileExporter, error) {
+	e := &FileExporter{path: path, now: time.Now}
+	for _, opt := range opts {
+		opt(&e.rotation)
+	}
+
+	if err := e.open(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// ExportSpans writes the spans to the file, one JSON object per line.
+func (e *FileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.shutdown {
+		return errors.ErrExporterShutdown
+	}
+
+	// Reopen the file if it could not be reopened after the last rotation
+	if e.file == nil {
+		if err := e.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, span := range spans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := json.Marshal(tracetest.SpanStubFromReadOnlySpan(span))
+		if err != nil {
+			return err
+		}
+
+		line = append(line, '\n')
+
+		if e.shouldRotate(int64(len(line))) {
+			if err := e.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := e.file.Write(line)
+		e.size += int64(n)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Shutdown closes the file and waits for rotated files to be compressed and pruned. Spans
+// exported afterwards are rejected.
+func (e *FileExporter) Shutdown(context.Context) error {
+	err := e.close()
+	e.backupWG.Wait()
+
+	return err
+}
+
+// close closes the file and rejects later exports.
+func (e *FileExporter) close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.shutdown = true
+
+	if e.file == nil {
+		return nil
+	}
+
+	err := e.file.Close()
+	e.file = nil
+
+	return err
+}
+
+// shouldRotate reports whether the file must be rotated before writing n more bytes.
+func (e *FileExporter) shouldRotate(n int64) bool {
+	if e.size == 0 {
+		return false
+	}
+
+	if e.rotation.maxSize > 0 && e.size+n > e.rotation.maxSize {
+		return true
+	}
+
+	return e.rotation.maxAge > 0 && e.now().Sub(e.openedAt) >= e.rotation.maxAge
+}
+
+// open opens the file for appending.
+func (e *FileExporter) open() error {
+	const filemode = 0o644
+
+	file, err := os.OpenFile(e.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filemode)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	e.file = file
+	e.size = info.Size()
+	e.openedAt = e.now()
+
+	// Age an existing file from its last write, so that reopening it after a restart does
+	// not postpone its rotation
+	if e.size > 0 {
+		e.openedAt = info.ModTime()
+	}
+
+	return nil
+}
+
+// rotate renames the current file to a timestamped backup and opens a new file straight
+// away. The backup is compressed and the old backups are pruned in the background.
+func (e *FileExporter) rotate() error {
+	closeErr := e.file.Close()
+	e.file = nil
+
+	// Keep backup names unique even if the clock does not advance between rotations
+	rotated := e.now().UTC()
+	if !rotated.After(e.rotated) {
+		rotated = e.rotated.Add(time.Nanosecond)
+	}
+
+	e.rotated = rotated
+
+	ext := filepath.Ext(e.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(e.path, ext), rotated.Format(backupTimeFormat), ext)
+	renameErr := os.Rename(e.path, backup)
+
+	// A failed rename leaves the spans in the current file, which is reopened and grows
+	if err := e.open(); err != nil {
+		return err
+	}
+
+	if err := stderrors.Join(closeErr, renameErr); err != nil {
+		otel.Handle(fmt.Errorf("%w: %w", errors.ErrFileRotation, err))
+		return nil
+	}
+
+	e.backupWG.Add(1)
+
+	go func() {
+		defer e.backupWG.Done()
+
+		e.processBackup(backup)
+	}()
+
+	return nil
+}
+
+// processBackup compresses a rotated file and prunes the old backups as configured,
+// reporting failures through otel.Handle.
+func (e *FileExporter) processBackup(backup string) {
+	e.backupMu.Lock()
+	defer e.backupMu.Unlock()
+
+	if e.rotation.compress {
+		if err := compressFile(backup); err != nil {
+			otel.Handle(fmt.Errorf("%w: %w", errors.ErrFileRotation, err))
+		}
+	}
+
+	if err := e.removeOldBackups(); err != nil {
+		otel.Handle(fmt.Errorf("%w: %w", errors.ErrFileRotation, err))
+	}
+}
+
+// backups returns the rotated files, oldest first. Only files named after the active file
+// and a rotation timestamp are returned, so that unrelated files are never pruned.
+func (e *FileExporter) backups() ([]string, error) {
+	ext := filepath.Ext(e.path)
+	prefix := strings.TrimSuffix(e.path, ext) + "-"
+
+	matches, err := filepath.Glob(globEscape(prefix) + "*" + globEscape(ext) + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]string, 0, len(matches))
+
+	for _, match := range matches {
+		name := strings.TrimPrefix(match, prefix)
+		name = strings.TrimSuffix(name, ".gz")
+
+		timestamp, ok := strings.CutSuffix(name, ext)
+		if !ok {
+			continue
+		}
+
+		if _, err := time.Parse(backupTimeFormat, timestamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+
+	slices.Sort(backups)
+
+	return backups, nil
+}
+
+// globEscape escapes the characters of s that filepath.Glob would treat as a pattern, so
+// that paths such as "traces[1].jsonl" match literally.
+func globEscape(s string) string {
+	var b strings.Builder
+
+	for _, c := range s {
+		switch {
+		case c == '*' || c == '?' || c == '[':
+			b.WriteString("[" + string(c) + "]")
+		case c == '\\' && runtime.GOOS != "windows":
+			b.WriteString(`\\`)
+		default:
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
+
+// removeOldBackups deletes the oldest rotated files beyond the configured maximum.
+func (e *FileExporter) removeOldBackups() error {
+	if e.rotation.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := e.backups()
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > e.rotation.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// compressFile gzips the file at path to path+".gz" and removes the original.
+func compressFile(path string) error {
+	const filemode = 0o644
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, filemode)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// Ensure FileExporter implements sdktrace.SpanExporter
+var _ sdktrace.SpanExporter = (*FileExporter)(nil)
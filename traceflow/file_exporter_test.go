@@ -0,0 +1,292 @@
+package traceflow
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// readSpanNames returns the span names in a JSON-lines file, failing on malformed lines.
+func readSpanNames(t *testing.T, r io.Reader) []string {
+	t.Helper()
+
+	var names []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var span struct{ Name string }
+
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span), "Expected one JSON span per line")
+
+		names = append(names, span.Name)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return names
+}
+
+// readSpanFile returns the span names in the file at path.
+func readSpanFile(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		require.NoError(t, err)
+
+		return readSpanNames(t, gz)
+	}
+
+	return readSpanNames(t, file)
+}
+
+// exportNamed exports one span per name.
+func exportNamed(t *testing.T, exporter *FileExporter, names ...string) {
+	t.Helper()
+
+	stubs := make(tracetest.SpanStubs, len(names))
+	for i, name := range names {
+		stubs[i] = tracetest.SpanStub{Name: name}
+	}
+
+	require.NoError(t, exporter.ExportSpans(context.Background(), stubs.Snapshots()))
+}
+
+// TestFileExporterJSONLines tests that each span is written as one compact JSON line and
+// that existing content is appended to.
+func TestFileExporterJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	exporter, err := NewFileExporter(path)
+	require.NoError(t, err)
+
+	exportNamed(t, exporter, "first", "second")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	exporter, err = NewFileExporter(path)
+	require.NoError(t, err)
+
+	exportNamed(t, exporter, "third")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"first", "second", "third"}, readSpanFile(t, path))
+
+	err = exporter.ExportSpans(context.Background(), tracetest.SpanStubs{{Name: "late"}}.Snapshots())
+	assert.ErrorIs(t, err, errors.ErrExporterShutdown)
+}
+
+// TestFileExporterSizeRotation tests that files are rotated by size and that only the
+// newest backups are kept.
+func TestFileExporterSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+
+	exporter, err := NewFileExporter(path, WithFileMaxSize(1), WithFileMaxBackups(2))
+	require.NoError(t, err)
+
+	// Every span exceeds the size limit, so each one ends up in its own file
+	exportNamed(t, exporter, "span-1", "span-2", "span-3", "span-4")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	backups, err := exporter.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	assert.Equal(t, []string{"span-2"}, readSpanFile(t, backups[0]))
+	assert.Equal(t, []string{"span-3"}, readSpanFile(t, backups[1]))
+	assert.Equal(t, []string{"span-4"}, readSpanFile(t, path))
+}
+
+// TestFileExporterAgeRotation tests that files are rotated by age and gzipped.
+func TestFileExporterAgeRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	exporter, err := NewFileExporter(path, WithFileMaxAge(time.Hour), WithFileCompression())
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	exporter.now = func() time.Time { return now }
+	exporter.openedAt = now
+
+	exportNamed(t, exporter, "early", "still-early")
+
+	now = now.Add(time.Hour)
+	exportNamed(t, exporter, "late")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	backups, err := exporter.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	assert.Equal(t, strings.TrimSuffix(path, ".jsonl")+"-2024-01-02T16-00-00.000000000.jsonl.gz", backups[0])
+	assert.Equal(t, []string{"early", "still-early"}, readSpanFile(t, backups[0]))
+	assert.Equal(t, []string{"late"}, readSpanFile(t, path))
+}
+
+// TestFileExporterAgeAcrossRestarts tests that reopening an existing file keeps its age
+// from its last write.
+func TestFileExporterAgeAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Name":"before-restart"}`+"\n"), 0o600))
+
+	lastWrite := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(path, lastWrite, lastWrite))
+
+	exporter, err := NewFileExporter(path, WithFileMaxAge(time.Hour))
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, lastWrite, exporter.openedAt, time.Second)
+
+	exportNamed(t, exporter, "after-restart")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	backups, err := exporter.backups()
+	require.NoError(t, err)
+
+	if assert.Len(t, backups, 1, "Expected the old file to be rotated on the first export") {
+		assert.Equal(t, []string{"before-restart"}, readSpanFile(t, backups[0]))
+	}
+
+	assert.Equal(t, []string{"after-restart"}, readSpanFile(t, path))
+}
+
+// TestFileExporterPatternPath tests that backups are found and pruned when the path
+// contains glob metacharacters.
+func TestFileExporterPatternPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs[*]")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+
+	path := filepath.Join(dir, "traces[1]?.jsonl")
+
+	exporter, err := NewFileExporter(path, WithFileMaxSize(1), WithFileMaxBackups(1))
+	require.NoError(t, err)
+
+	exportNamed(t, exporter, "span-1", "span-2", "span-3")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	backups, err := exporter.backups()
+	require.NoError(t, err)
+
+	if assert.Len(t, backups, 1) {
+		assert.Equal(t, []string{"span-2"}, readSpanFile(t, backups[0]))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "Expected the oldest backup to be pruned")
+}
+
+// TestFileExporterRotationFailure tests that a failed rotation is reported and that spans
+// keep being written to the active file.
+func TestFileExporterRotationFailure(t *testing.T) {
+	var reported []error
+
+	previous := otel.GetErrorHandler()
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) { reported = append(reported, err) }))
+	t.Cleanup(func() { otel.SetErrorHandler(previous) })
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	exporter, err := NewFileExporter(path, WithFileMaxSize(1))
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	exporter.now = func() time.Time { return now }
+
+	// A non-empty directory where the backup would go makes the rename fail
+	blocked := strings.TrimSuffix(path, ".jsonl") + "-2024-01-02T15-00-00.000000000.jsonl"
+	require.NoError(t, os.MkdirAll(filepath.Join(blocked, "keep"), 0o755))
+
+	exportNamed(t, exporter, "first", "second")
+
+	assert.Equal(t, []string{"first", "second"}, readSpanFile(t, path),
+		"Expected spans to be written to the active file after the failed rotation")
+
+	if assert.Len(t, reported, 1) {
+		assert.ErrorIs(t, reported[0], errors.ErrFileRotation)
+	}
+
+	// The next rotation goes to a new backup name and succeeds
+	now = now.Add(time.Second)
+
+	exportNamed(t, exporter, "third")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	backup := strings.TrimSuffix(path, ".jsonl") + "-2024-01-02T15-00-01.000000000.jsonl"
+
+	assert.Equal(t, []string{"first", "second"}, readSpanFile(t, backup))
+	assert.Equal(t, []string{"third"}, readSpanFile(t, path))
+}
+
+// TestFileExporterPrunesOnlyBackups tests that pruning leaves files that were not created
+// by rotation alone.
+func TestFileExporterPrunesOnlyBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+	unrelated := []string{"traces-archive.jsonl", "traces-2024.jsonl.gz", "traces-old.jsonl.bak"}
+
+	for _, name := range unrelated {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("keep\n"), 0o600))
+	}
+
+	exporter, err := NewFileExporter(path, WithFileMaxSize(1), WithFileMaxBackups(1), WithFileCompression())
+	require.NoError(t, err)
+
+	exportNamed(t, exporter, "span-1", "span-2", "span-3")
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	backups, err := exporter.backups()
+	require.NoError(t, err)
+
+	if assert.Len(t, backups, 1) {
+		assert.Equal(t, []string{"span-2"}, readSpanFile(t, backups[0]))
+	}
+
+	for _, name := range unrelated {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+}
+
+// TestWithFileLoggingKeepsLogsSeparate tests that library log messages are not written into
+// the span file.
+func TestWithFileLoggingKeepsLogsSeparate(t *testing.T) {
+	var logOutput bytes.Buffer
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	previous := otel.GetTracerProvider()
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		WithLogger(log.New(&logOutput, "", 0)),
+		WithFileLogging(path, WithFileMaxSize(1<<20)),
+	)
+	require.NoError(t, err)
+
+	New(ctx, "test-service").Start("exported").End()
+	require.NoError(t, shutdown(ctx))
+
+	assert.Equal(t, []string{"test-service.exported"}, readSpanFile(t, path))
+	assert.Contains(t, logOutput.String(), "OpenTelemetry initialized successfully")
+}
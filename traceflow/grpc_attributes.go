@@ -2,11 +2,18 @@ package traceflow
 
 import (
 	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
 
-	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // InjectGRPCContext injects the trace context into the gRPC metadata.
@@ -47,7 +54,8 @@ import (
 //   - If the trace context (`t.ctx`) is nil or not properly initialized, this method
 //     is a no-op and does not modify the outgoing context.
 func (t *Trace) InjectGRPCContext(ctx context.Context) context.Context {
-	if t.ctx == nil {
+	traceCtx := t.GetContext()
+	if traceCtx == nil {
 		return ctx
 	}
 
@@ -67,8 +75,8 @@ func (t *Trace) InjectGRPCContext(ctx context.Context) context.Context {
 	}
 
 	carrier := propagation.MapCarrier(mdMap)
-	propagator := otel.GetTextMapPropagator()
-	propagator.Inject(t.ctx, carrier)
+	propagator := t.telemetry.Propagator()
+	propagator.Inject(traceCtx, carrier)
 
 	for k, v := range mdMap {
 		md.Set(k, v)
@@ -77,51 +85,202 @@ func (t *Trace) InjectGRPCContext(ctx context.Context) context.Context {
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
-// UnaryClientInterceptor is a gRPC client interceptor that injects the trace context into
-// outgoing gRPC requests. This allows the gRPC client to propagate the trace context to
-// downstream services, ensuring that traces can be linked across distributed services.
+// UnaryClientInterceptor is a gRPC client interceptor that traces every outgoing unary RPC
+// with its own Client span. The span is parented to the span in the call's context, named
+// after the full method, and its context is injected into the outgoing gRPC metadata so
+// downstream services can continue the trace.
 //
-// The interceptor intercepts every gRPC client call, extracts the current trace context
-// from the client's context, and injects it into the outgoing gRPC metadata. This is
-// essential for maintaining end-to-end visibility in distributed systems, allowing the
-// trace to continue across multiple service boundaries.
+// The rpc.system, rpc.service, rpc.method, and resulting status code are recorded, and any
+// non-OK status marks the span as Error. Any options are applied to every span.
 //
 // Example usage:
 //
 //	opts := []grpc.DialOption{
-//	    grpc.WithUnaryInterceptor(traceflow.UnaryClientInterceptor()),
+//	    grpc.WithChainUnaryInterceptor(traceflow.UnaryClientInterceptor("user-client")),
+//	    grpc.WithChainStreamInterceptor(traceflow.StreamClientInterceptor("user-client")),
 //	}
-//	conn, err := grpc.Dial("localhost:50051", opts...)
+//	conn, err := grpc.NewClient("localhost:50051", opts...)
 //	if err != nil {
 //	    log.Fatalf("Failed to connect: %v", err)
 //	}
 //	defer conn.Close()
 //
 //	client := pb.NewMyServiceClient(conn)
-//	// Now all outgoing requests will have trace context injected
+//	// Now every call gets a client span and propagates the trace context
 //
 // Notes:
-//   - This interceptor is designed for unary RPCs. For streaming RPCs, a different
-//     interceptor (e.g., `StreamClientInterceptor`) is required.
+//   - This interceptor is designed for unary RPCs. For streaming RPCs, use
+//     StreamClientInterceptor.
 //   - The trace context is injected using OpenTelemetry's propagator, and the trace context
 //     is transmitted in a format that follows the W3C Trace Context standard.
-//   - This interceptor should be included as part of the gRPC client options.
 //
 // Returns:
 //   - A gRPC `grpc.UnaryClientInterceptor` function that can be added to the gRPC client
-//     configuration to enable automatic trace context propagation.
-func UnaryClientInterceptor(trace *Trace) grpc.UnaryClientInterceptor {
+//     configuration to enable automatic client spans and trace context propagation.
+func UnaryClientInterceptor(service string, opts ...Option) grpc.UnaryClientInterceptor {
 	return func(
 		ctx context.Context,
 		method string,
 		req, reply interface{},
 		cc *grpc.ClientConn,
 		invoker grpc.UnaryInvoker,
-		opts ...grpc.CallOption,
+		callOpts ...grpc.CallOption,
 	) error {
-		// Inject the trace context into gRPC metadata
-		ctx = trace.InjectGRPCContext(ctx)
+		trace := startGRPCClientSpan(ctx, service, method, opts)
+		defer trace.EndWithRecover()
 
-		return invoker(ctx, method, req, reply, cc, opts...)
+		// Inject the client span's context into gRPC metadata
+		err := invoker(trace.InjectGRPCContext(ctx), method, req, reply, cc, callOpts...)
+		trace.setGRPCClientStatus(err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor is a gRPC client interceptor that traces every outgoing streaming
+// RPC with its own Client span, in the same way as UnaryClientInterceptor. The number of
+// messages sent and received is recorded, and the span ends when the stream finishes: when
+// a receive returns io.EOF or an error, when the single response of a stream without server
+// streaming is received, or when the caller's context is cancelled.
+func StreamClientInterceptor(service string, opts ...Option) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		trace := startGRPCClientSpan(ctx, service, method, opts)
+
+		stream, err := streamer(trace.InjectGRPCContext(ctx), desc, cc, method, callOpts...)
+		if err != nil {
+			trace.setGRPCClientStatus(err)
+			trace.End()
+
+			return nil, err
+		}
+
+		traced := &tracedClientStream{
+			ClientStream:  stream,
+			trace:         trace,
+			serverStreams: desc.ServerStreams,
+			done:          make(chan struct{}),
+		}
+
+		// End the span if the caller cancels the stream before it is drained. grpc-go also
+		// cancels the stream context when the stream completes on its own, in which case the
+		// outcome is reported by RecvMsg and SendMsg.
+		go func() {
+			select {
+			case <-traced.done:
+			case <-stream.Context().Done():
+				if err := ctx.Err(); err != nil {
+					traced.finish(status.FromContextError(err).Err())
+				}
+			}
+		}()
+
+		return traced, nil
+	}
+}
+
+// startGRPCClientSpan starts a Client span for an outgoing RPC.
+func startGRPCClientSpan(ctx context.Context, service, fullMethod string, opts []Option) *Trace {
+	rpcService, rpcMethod := parseFullMethod(fullMethod)
+
+	return New(ctx, service, opts...).
+		Client().
+		AddAttribute(
+			AddString("rpc.system", "grpc"),
+			AddString("rpc.service", rpcService),
+			AddString("rpc.method", rpcMethod),
+		).
+		Start(strings.TrimPrefix(fullMethod, "/"))
+}
+
+// setGRPCClientStatus records the gRPC status code of a call result and sets the span
+// status. Any non-OK code marks a client span as Error.
+func (t *Trace) setGRPCClientStatus(err error) {
+	code := status.Code(err)
+
+	t.AddAttribute(AddInt("rpc.grpc.status_code", int(code)))
+
+	if code == grpccodes.OK {
+		t.SetSuccess(code.String())
+		return
+	}
+
+	t.RecordError(err)
+	t.SetStatus(codes.Error, status.Convert(err).Message())
+}
+
+// tracedClientStream wraps a grpc.ClientStream to count messages and end the span when
+// the stream finishes.
+type tracedClientStream struct {
+	grpc.ClientStream
+	trace         *Trace
+	serverStreams bool
+	sent          atomic.Int64
+	received      atomic.Int64
+	once          sync.Once
+	done          chan struct{}
+}
+
+// SendMsg counts sent messages and finishes the span if the stream fails.
+func (s *tracedClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	} else if !errors.Is(err, io.EOF) {
+		// io.EOF means the stream was aborted and the real status comes from RecvMsg
+		s.finish(err)
+	}
+
+	return err
+}
+
+// RecvMsg counts received messages and finishes the span at the end of the stream.
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	switch {
+	case err == nil:
+		s.received.Add(1)
+
+		// Without server streaming, the single response ends the stream
+		if !s.serverStreams {
+			s.finish(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	default:
+		s.finish(err)
 	}
+
+	return err
+}
+
+// Header finishes the span if the stream failed before headers were received.
+func (s *tracedClientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	if err != nil {
+		s.finish(err)
+	}
+
+	return md, err
+}
+
+// finish records the outcome of the stream and ends the span exactly once.
+func (s *tracedClientStream) finish(err error) {
+	s.once.Do(func() {
+		s.trace.AddAttribute(
+			AddInt("rpc.messages_sent", int(s.sent.Load())),
+			AddInt("rpc.messages_received", int(s.received.Load())),
+		)
+		s.trace.setGRPCClientStatus(err)
+		s.trace.End()
+
+		close(s.done)
+	})
 }
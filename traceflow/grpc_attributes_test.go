@@ -2,12 +2,27 @@ package traceflow
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestInjectGRPCContext(t *testing.T) {
@@ -42,3 +57,317 @@ func TestInjectGRPCContext(t *testing.T) {
 	tp := otel.GetTracerProvider()
 	assert.NotEqual(t, noop.NewTracerProvider(), tp, "Expected a valid TracerProvider to be set")
 }
+
+// startTestGRPCServer starts an in-memory gRPC server with traceflow server interceptors,
+// a health service, a bidirectional echo stream registered as "/test.Echo/Echo", a client
+// stream answering once as "/test.Echo/Collect" and a server stream answering twice as
+// "/test.Echo/List". It returns a client connection using the traceflow client interceptors.
+func startTestGRPCServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor("test-server")),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor("test-server")),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+
+			switch method {
+			case "/test.Echo/Collect":
+				for {
+					var msg grpc_health_v1.HealthCheckRequest
+					if err := stream.RecvMsg(&msg); errors.Is(err, io.EOF) {
+						break
+					} else if err != nil {
+						return err
+					}
+				}
+
+				return stream.SendMsg(&grpc_health_v1.HealthCheckResponse{})
+			case "/test.Echo/List":
+				var msg grpc_health_v1.HealthCheckRequest
+				if err := stream.RecvMsg(&msg); err != nil {
+					return err
+				}
+
+				for range 2 {
+					if err := stream.SendMsg(&grpc_health_v1.HealthCheckResponse{}); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			}
+
+			for {
+				var msg grpc_health_v1.HealthCheckRequest
+
+				err := stream.RecvMsg(&msg)
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+
+				if err != nil {
+					return err
+				}
+
+				if err := stream.SendMsg(&grpc_health_v1.HealthCheckResponse{}); err != nil {
+					return err
+				}
+			}
+		}),
+	)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("users", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor("test-client")),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor("test-client")),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create gRPC client: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return conn
+}
+
+// spanByName returns the exported span with the given name.
+func spanByName(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	t.Fatalf("Expected a span named %q, got %d spans", name, len(spans))
+
+	return tracetest.SpanStub{}
+}
+
+// TestUnaryClientInterceptor tests that every unary call gets its own client span that
+// is propagated to the server.
+func TestUnaryClientInterceptor(t *testing.T) {
+	exporter := useRecordingProvider(t)
+	useTraceContextPropagator(t)
+
+	client := grpc_health_v1.NewHealthClient(startTestGRPCServer(t))
+	parent := Now(context.Background(), "test-service", "parent")
+
+	_, err := client.Check(parent.GetContext(), &grpc_health_v1.HealthCheckRequest{Service: "users"})
+	assert.NoError(t, err)
+
+	_, err = client.Check(parent.GetContext(), &grpc_health_v1.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, grpccodes.NotFound, status.Code(err))
+
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 5) {
+		return
+	}
+
+	var clientSpans, serverSpans []tracetest.SpanStub
+
+	for _, span := range spans {
+		switch span.SpanKind {
+		case oteltrace.SpanKindClient:
+			clientSpans = append(clientSpans, span)
+		case oteltrace.SpanKindServer:
+			serverSpans = append(serverSpans, span)
+		default:
+		}
+	}
+
+	if !assert.Len(t, clientSpans, 2) || !assert.Len(t, serverSpans, 2) {
+		return
+	}
+
+	parentSpan := spanByName(t, spans, "test-service.parent")
+
+	assert.NotEqual(t, clientSpans[0].SpanContext.SpanID(), clientSpans[1].SpanContext.SpanID(),
+		"Expected a fresh client span per call")
+
+	for i, clientSpan := range clientSpans {
+		assert.Equal(t, "test-client.grpc.health.v1.Health/Check", clientSpan.Name)
+		assert.Equal(t, parentSpan.SpanContext.SpanID(), clientSpan.Parent.SpanID())
+		assert.Equal(t, clientSpan.SpanContext.SpanID(), serverSpans[i].Parent.SpanID(),
+			"Expected the server span to be parented to the client span")
+	}
+
+	assert.Equal(t, codes.Ok, clientSpans[0].Status.Code)
+	assert.Equal(t, codes.Error, clientSpans[1].Status.Code)
+
+	if v, _ := findAttribute(clientSpans[1], "rpc.grpc.status_code"); v.AsInt64() != int64(grpccodes.NotFound) {
+		t.Errorf("Expected NotFound status code, got %v", v)
+	}
+}
+
+// TestStreamClientInterceptor tests that streaming calls record message counts and end
+// the span when the stream is drained.
+func TestStreamClientInterceptor(t *testing.T) {
+	exporter := useRecordingProvider(t)
+	useTraceContextPropagator(t)
+
+	conn := startTestGRPCServer(t)
+	desc := &grpc.StreamDesc{StreamName: "Echo", ServerStreams: true, ClientStreams: true}
+
+	stream, err := conn.NewStream(context.Background(), desc, "/test.Echo/Echo")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, stream.SendMsg(&grpc_health_v1.HealthCheckRequest{}))
+	}
+
+	assert.NoError(t, stream.CloseSend())
+
+	for {
+		var resp grpc_health_v1.HealthCheckResponse
+
+		if err := stream.RecvMsg(&resp); err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+	}
+
+	spans := exporter.GetSpans()
+	clientSpan := spanByName(t, spans, "test-client.test.Echo/Echo")
+	serverSpan := spanByName(t, spans, "test-server.test.Echo/Echo")
+
+	assert.Equal(t, codes.Ok, clientSpan.Status.Code)
+	assert.Equal(t, clientSpan.SpanContext.SpanID(), serverSpan.Parent.SpanID())
+
+	if v, _ := findAttribute(clientSpan, "rpc.messages_sent"); v.AsInt64() != 2 {
+		t.Errorf("Expected 2 messages sent, got %v", v)
+	}
+
+	if v, _ := findAttribute(clientSpan, "rpc.messages_received"); v.AsInt64() != 2 {
+		t.Errorf("Expected 2 messages received, got %v", v)
+	}
+}
+
+// assertStreamSucceeded asserts that every client span of the stream recorded an OK status.
+func assertStreamSucceeded(t *testing.T, spans tracetest.SpanStubs, name string, calls int) {
+	t.Helper()
+
+	var found int
+
+	for _, span := range spans {
+		if span.Name != name {
+			continue
+		}
+
+		found++
+
+		assert.Equal(t, codes.Ok, span.Status.Code, "Unexpected status %q", span.Status.Description)
+		assert.Empty(t, span.Events, "Expected no error to be recorded")
+
+		if v, _ := findAttribute(span, "rpc.grpc.status_code"); v.AsInt64() != int64(grpccodes.OK) {
+			t.Errorf("Expected the OK status code, got %v", v)
+		}
+	}
+
+	assert.Equal(t, calls, found)
+}
+
+// TestStreamClientInterceptorClientStreaming tests that a client stream ends its span as
+// successful once its single response is received, although grpc-go cancels the stream
+// context when it completes.
+func TestStreamClientInterceptorClientStreaming(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	conn := startTestGRPCServer(t)
+	desc := &grpc.StreamDesc{StreamName: "Collect", ClientStreams: true}
+
+	const calls = 20
+
+	for range calls {
+		stream, err := conn.NewStream(context.Background(), desc, "/test.Echo/Collect")
+		require.NoError(t, err)
+
+		require.NoError(t, stream.SendMsg(&grpc_health_v1.HealthCheckRequest{}))
+		require.NoError(t, stream.CloseSend())
+		require.NoError(t, stream.RecvMsg(&grpc_health_v1.HealthCheckResponse{}))
+	}
+
+	// Let a stray cancellation of the stream context be recorded before checking the spans
+	time.Sleep(50 * time.Millisecond)
+
+	assertStreamSucceeded(t, exporter.GetSpans(), "test-client.test.Echo/Collect", calls)
+}
+
+// TestStreamClientInterceptorServerStreaming tests that a drained server stream ends its span
+// as successful rather than cancelled.
+func TestStreamClientInterceptorServerStreaming(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	conn := startTestGRPCServer(t)
+	desc := &grpc.StreamDesc{StreamName: "List", ServerStreams: true}
+
+	const calls = 20
+
+	for range calls {
+		stream, err := conn.NewStream(context.Background(), desc, "/test.Echo/List")
+		require.NoError(t, err)
+
+		require.NoError(t, stream.SendMsg(&grpc_health_v1.HealthCheckRequest{}))
+		require.NoError(t, stream.CloseSend())
+
+		for {
+			if err := stream.RecvMsg(&grpc_health_v1.HealthCheckResponse{}); err != nil {
+				require.ErrorIs(t, err, io.EOF)
+				break
+			}
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	assertStreamSucceeded(t, exporter.GetSpans(), "test-client.test.Echo/List", calls)
+}
+
+// TestStreamClientInterceptorCancel tests that cancelled streams still end their span.
+func TestStreamClientInterceptorCancel(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	conn := startTestGRPCServer(t)
+	desc := &grpc.StreamDesc{StreamName: "Echo", ServerStreams: true, ClientStreams: true}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err := conn.NewStream(ctx, desc, "/test.Echo/Echo")
+	if !assert.NoError(t, err) {
+		cancel()
+		return
+	}
+
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		for _, span := range exporter.GetSpans() {
+			if span.Name == "test-client.test.Echo/Echo" {
+				return span.Status.Code == codes.Error
+			}
+		}
+
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,125 @@
+package traceflow
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a gRPC server interceptor that traces every unary RPC
+// with a Server span. The trace context is extracted from the incoming metadata, the span
+// is named after the full method, and the rpc.system, rpc.service, rpc.method, and
+// resulting status code are recorded. The span status is set from the gRPC status code.
+//
+// The *Trace for the call is stored in the handler's context and can be retrieved with
+// FromContext. Any options are applied to every span.
+//
+// Example usage:
+//
+//	server := grpc.NewServer(
+//	    grpc.ChainUnaryInterceptor(traceflow.UnaryServerInterceptor("user-service")),
+//	    grpc.ChainStreamInterceptor(traceflow.StreamServerInterceptor("user-service")),
+//	)
+func UnaryServerInterceptor(service string, opts ...Option) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		trace := startGRPCServerSpan(ctx, service, info.FullMethod, opts)
+		defer trace.EndWithRecover()
+
+		resp, err := handler(ContextWithTrace(trace.GetContext(), trace), req)
+		trace.setGRPCServerStatus(err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC server interceptor that traces every streaming
+// RPC with a Server span, in the same way as UnaryServerInterceptor. The span covers the
+// lifetime of the stream and ends when the handler returns.
+func StreamServerInterceptor(service string, opts ...Option) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		trace := startGRPCServerSpan(ss.Context(), service, info.FullMethod, opts)
+		defer trace.EndWithRecover()
+
+		err := handler(srv, &tracedServerStream{
+			ServerStream: ss,
+			ctx:          ContextWithTrace(trace.GetContext(), trace),
+		})
+		trace.setGRPCServerStatus(err)
+
+		return err
+	}
+}
+
+// startGRPCServerSpan starts a Server span for an incoming RPC.
+func startGRPCServerSpan(ctx context.Context, service, fullMethod string, opts []Option) *Trace {
+	rpcService, rpcMethod := parseFullMethod(fullMethod)
+
+	// Extract after the options, so that the propagator set by WithTelemetry is used
+	opts = append(slices.Clone(opts), withGRPCContext())
+
+	return New(ctx, service, opts...).
+		Server().
+		AddAttribute(
+			AddString("rpc.system", "grpc"),
+			AddString("rpc.service", rpcService),
+			AddString("rpc.method", rpcMethod),
+		).
+		Start(strings.TrimPrefix(fullMethod, "/"))
+}
+
+// setGRPCServerStatus records the gRPC status code of a handler result and sets the span
+// status. Only codes that indicate a server-side failure mark the span as Error.
+func (t *Trace) setGRPCServerStatus(err error) {
+	code := status.Code(err)
+
+	t.AddAttribute(AddInt("rpc.grpc.status_code", int(code)))
+
+	switch code {
+	case grpccodes.OK:
+		t.SetSuccess(code.String())
+	case grpccodes.Unknown, grpccodes.DeadlineExceeded, grpccodes.Unimplemented,
+		grpccodes.Internal, grpccodes.Unavailable, grpccodes.DataLoss:
+		t.RecordError(err)
+		t.SetStatus(codes.Error, status.Convert(err).Message())
+	default:
+		// Client errors such as NotFound or InvalidArgument are not server failures
+	}
+}
+
+// parseFullMethod splits a gRPC full method name ("/package.Service/Method") into its
+// service and method parts.
+func parseFullMethod(fullMethod string) (string, string) {
+	name := strings.TrimPrefix(fullMethod, "/")
+
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+
+	return name, ""
+}
+
+// tracedServerStream wraps a grpc.ServerStream to expose the traced context to handlers.
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the server span.
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,147 @@
+package traceflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// TestUnaryServerInterceptor tests that unary RPCs are traced with a Server span that
+// continues the incoming trace.
+func TestUnaryServerInterceptor(t *testing.T) {
+	exporter := useRecordingProvider(t)
+	useTraceContextPropagator(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", testTraceparent))
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/GetUser"}
+
+	resp, err := UnaryServerInterceptor("test-service")(ctx, "request", info,
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			_, ok := FromContext(ctx)
+			assert.True(t, ok, "Expected the trace to be stored in the handler context")
+
+			return "response", nil
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "response", resp)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	span := spans[0]
+	assert.Equal(t, "test-service.users.v1.UserService/GetUser", span.Name)
+	assert.Equal(t, oteltrace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, codes.Ok, span.Status.Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+
+	expected := []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", "users.v1.UserService"),
+		attribute.String("rpc.method", "GetUser"),
+		attribute.Int("rpc.grpc.status_code", int(grpccodes.OK)),
+	}
+
+	for _, attr := range expected {
+		if v, ok := findAttribute(span, attr.Key); !ok || v != attr.Value {
+			t.Errorf("Expected attribute %v, got %v", attr, v)
+		}
+	}
+}
+
+// TestUnaryServerInterceptorStatus tests how gRPC status codes map to span status.
+func TestUnaryServerInterceptorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{name: "internal", err: status.Error(grpccodes.Internal, "boom"), expected: codes.Error},
+		{name: "unavailable", err: status.Error(grpccodes.Unavailable, "down"), expected: codes.Error},
+		{name: "not found", err: status.Error(grpccodes.NotFound, "missing"), expected: codes.Unset},
+		{name: "plain error", err: assert.AnError, expected: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := useRecordingProvider(t)
+			info := &grpc.UnaryServerInfo{FullMethod: "/users.v1.UserService/GetUser"}
+
+			_, err := UnaryServerInterceptor("test-service")(context.Background(), nil, info,
+				func(context.Context, interface{}) (interface{}, error) {
+					return nil, tt.err
+				})
+
+			assert.Equal(t, tt.err, err)
+
+			spans := exporter.GetSpans()
+			if !assert.Len(t, spans, 1) {
+				return
+			}
+
+			assert.Equal(t, tt.expected, spans[0].Status.Code)
+
+			if v, _ := findAttribute(spans[0], "rpc.grpc.status_code"); v.AsInt64() != int64(status.Code(tt.err)) {
+				t.Errorf("Expected status code %d, got %v", status.Code(tt.err), v)
+			}
+		})
+	}
+}
+
+// fakeServerStream is a minimal grpc.ServerStream for exercising stream interceptors.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// TestStreamServerInterceptor tests that streaming RPCs are traced for the lifetime of the stream.
+func TestStreamServerInterceptor(t *testing.T) {
+	exporter := useRecordingProvider(t)
+	useTraceContextPropagator(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", testTraceparent))
+	info := &grpc.StreamServerInfo{FullMethod: "/users.v1.UserService/ListUsers", IsServerStream: true}
+
+	err := StreamServerInterceptor("test-service")(nil, &fakeServerStream{ctx: ctx}, info,
+		func(_ interface{}, stream grpc.ServerStream) error {
+			trace, ok := FromContext(stream.Context())
+			if assert.True(t, ok, "Expected the trace to be stored in the stream context") {
+				trace.AddAttribute(AddInt("users.sent", 3))
+			}
+
+			return status.Error(grpccodes.DataLoss, "truncated")
+		})
+
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	assert.Equal(t, "test-service.users.v1.UserService/ListUsers", spans[0].Name)
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "truncated", spans[0].Status.Description)
+
+	if v, _ := findAttribute(spans[0], "users.sent"); v.AsInt64() != 3 {
+		t.Errorf("Expected users.sent attribute, got %v", v)
+	}
+}
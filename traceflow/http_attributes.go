@@ -1,16 +1,17 @@
 package traceflow
 
 import (
+	"maps"
 	"net/http"
+	"slices"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 )
 
 // AddHTTPRequest adds HTTP request details as attributes to the trace.
 func (t *Trace) AddHTTPRequest(req *http.Request) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("http.method", req.Method),
 		attribute.String("http.url", req.URL.String()),
 		attribute.String("http.user_agent", req.UserAgent()),
@@ -22,7 +23,7 @@ func (t *Trace) AddHTTPRequest(req *http.Request) *Trace {
 
 // AddHTTPResponse adds HTTP response details as attributes to the trace.
 func (t *Trace) AddHTTPResponse(statusCode int, contentLength int64) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.Int("http.status_code", statusCode),
 		attribute.Int64("http.content_length", contentLength),
 	)
@@ -32,12 +33,17 @@ func (t *Trace) AddHTTPResponse(statusCode int, contentLength int64) *Trace {
 
 // AddHTTPHeaders adds HTTP headers as attributes to the trace.
 func (t *Trace) AddHTTPHeaders(headers http.Header) *Trace {
-	for key, values := range headers {
-		for _, value := range values {
-			t.attrs = append(t.attrs, attribute.String("http.header."+key, value))
+	attrs := make([]attribute.KeyValue, 0, len(headers))
+
+	// Iterate in key order so the attributes are recorded deterministically
+	for _, key := range slices.Sorted(maps.Keys(headers)) {
+		for _, value := range headers[key] {
+			attrs = append(attrs, attribute.String("http.header."+key, value))
 		}
 	}
 
+	t.setAttributes(attrs...)
+
 	return t
 }
 
@@ -45,9 +51,10 @@ func (t *Trace) AddHTTPHeaders(headers http.Header) *Trace {
 // This ensures that the context of a trace is propagated across service boundaries in
 // distributed systems.
 //
-// The trace context is injected using the global propagator, which handles the
-// serialization of the trace context as HTTP headers. Users of traceflow do not need to
-// import or manage OpenTelemetry propagators directly.
+// The trace context is injected using the propagator of the trace's Telemetry (the global
+// propagator by default), which handles the serialization of the trace context as HTTP
+// headers. Users of traceflow do not need to import or manage OpenTelemetry propagators
+// directly.
 //
 // Example usage:
 //
@@ -58,14 +65,15 @@ func (t *Trace) AddHTTPHeaders(headers http.Header) *Trace {
 //   - The trace context is injected into the HTTP request's headers using the default
 //     W3C Trace Context format.
 func (t *Trace) InjectHTTPContext(req *http.Request) *Trace {
-	if t.ctx == nil {
+	ctx := t.GetContext()
+	if ctx == nil {
 		return t
 	}
 
 	// Use the internal OpenTelemetry propagator to inject the context
-	propagator := otel.GetTextMapPropagator()
+	propagator := t.telemetry.Propagator()
 	carrier := propagation.HeaderCarrier(req.Header)
-	propagator.Inject(t.ctx, carrier)
+	propagator.Inject(ctx, carrier)
 
 	return t
 }
@@ -74,8 +82,9 @@ func (t *Trace) InjectHTTPContext(req *http.Request) *Trace {
 // and updates the Trace's context. This ensures that the current service can join
 // an existing trace initiated by an upstream service.
 //
-// The trace context is extracted using the global propagator. Users of traceflow do
-// not need to interact with OpenTelemetry’s propagators directly.
+// The trace context is extracted using the propagator of the trace's Telemetry (the global
+// propagator by default). Users of traceflow do not need to interact with OpenTelemetry’s
+// propagators directly.
 //
 // Example usage:
 //
@@ -85,9 +94,9 @@ func (t *Trace) InjectHTTPContext(req *http.Request) *Trace {
 // Notes:
 // - This method updates the Trace's context (t.ctx) with the extracted trace context.
 func (t *Trace) ExtractHTTPContext(req *http.Request) *Trace {
-	propagator := otel.GetTextMapPropagator()
-	ctx := propagator.Extract(t.ctx, propagation.HeaderCarrier(req.Header))
-	t.ctx = ctx
+	propagator := t.telemetry.Propagator()
+	ctx := propagator.Extract(t.GetContext(), propagation.HeaderCarrier(req.Header))
+	t.setContext(ctx)
 
 	return t
 }
@@ -11,3 +11,84 @@ var ErrTraceExporterCreation = fmt.Errorf("failed to create trace exporter")
 
 // ErrStdOutExporter is returned when a stdout trace exporter cannot be created
 var ErrStdOutExporter = fmt.Errorf("failed to create stdout trace exporter")
+
+// ErrNoCertificates is returned when a CA file does not contain any PEM certificates
+var ErrNoCertificates = fmt.Errorf("no certificates found in CA file")
+
+// ErrInsecureEndpoint is returned when TLS options are set for an http:// OTLP endpoint
+var ErrInsecureEndpoint = fmt.Errorf("TLS options set for an insecure endpoint")
+
+// ErrMetricExporterCreation is returned when a metric exporter cannot be created
+var ErrMetricExporterCreation = fmt.Errorf("failed to create metric exporter")
+
+// ErrFileExporterCreation is returned when a file trace exporter cannot be created
+var ErrFileExporterCreation = fmt.Errorf("failed to create file trace exporter")
+
+// ErrTracerProviderShutdown is returned when the tracer provider fails to shut down
+var ErrTracerProviderShutdown = fmt.Errorf("failed to shut down tracer provider")
+
+// ErrMeterProviderShutdown is returned when the meter provider fails to shut down
+var ErrMeterProviderShutdown = fmt.Errorf("failed to shut down meter provider")
+
+// ErrTracerProviderFlush is returned when the tracer provider fails to flush its spans
+var ErrTracerProviderFlush = fmt.Errorf("failed to flush tracer provider")
+
+// ErrMeterProviderFlush is returned when the meter provider fails to flush its metrics
+var ErrMeterProviderFlush = fmt.Errorf("failed to flush meter provider")
+
+// ErrInvalidEnvironment is returned when an OTEL_* environment variable has an invalid value
+var ErrInvalidEnvironment = fmt.Errorf("invalid OpenTelemetry environment variable")
+
+// ErrUnsupportedProtocol is returned when an OTLP protocol is not supported
+var ErrUnsupportedProtocol = fmt.Errorf("unsupported OTLP protocol")
+
+// ErrUnknownSampler is returned when a sampler name is not recognized
+var ErrUnknownSampler = fmt.Errorf("unknown sampler")
+
+// ErrInvalidSamplerArg is returned when a sampler argument is not a valid ratio
+var ErrInvalidSamplerArg = fmt.Errorf("invalid sampler argument")
+
+// ErrUnknownPropagator is returned when a propagator name or type is not recognized
+var ErrUnknownPropagator = fmt.Errorf("unknown propagator")
+
+// ErrUnknownDetector is returned when a resource detector name is not recognized
+var ErrUnknownDetector = fmt.Errorf("unknown resource detector")
+
+// ErrInvalidKeyValue is returned when a key=value list entry cannot be parsed
+var ErrInvalidKeyValue = fmt.Errorf("invalid key=value pair")
+
+// ErrInvalidConfig is returned when a configuration file cannot be loaded
+var ErrInvalidConfig = fmt.Errorf("invalid configuration file")
+
+// ErrUnknownField is returned when a configuration file contains an unsupported field
+var ErrUnknownField = fmt.Errorf("unknown field")
+
+// ErrMissingField is returned when a required configuration field is not set
+var ErrMissingField = fmt.Errorf("field is required")
+
+// ErrInvalidValue is returned when a configuration field has an invalid value
+var ErrInvalidValue = fmt.Errorf("invalid value")
+
+// ErrUnknownExporter is returned when an exporter type is not recognized
+var ErrUnknownExporter = fmt.Errorf("unknown exporter type")
+
+// ErrUnsetVariable is returned when a configuration file references an unset environment variable
+var ErrUnsetVariable = fmt.Errorf("environment variable is not set")
+
+// ErrInvalidTailSampling is returned when WithTailSampling is configured so that it would drop every trace
+var ErrInvalidTailSampling = fmt.Errorf("invalid tail sampling configuration")
+
+// ErrFileRotation is reported when a trace file cannot be rotated, compressed or pruned
+var ErrFileRotation = fmt.Errorf("failed to rotate trace file")
+
+// ErrExporterShutdown is returned when spans are exported after the exporter was shut down
+var ErrExporterShutdown = fmt.Errorf("exporter is shut down")
+
+// ErrInvalidSpanRecord is returned when a span read back from disk cannot be decoded
+var ErrInvalidSpanRecord = fmt.Errorf("invalid span record")
+
+// ErrDiskBufferCreation is returned when the disk buffer of an exporter cannot be created
+var ErrDiskBufferCreation = fmt.Errorf("failed to create disk buffer")
+
+// ErrSpansDropped is reported when the disk buffer is full and spans are discarded
+var ErrSpansDropped = fmt.Errorf("disk buffer is full, spans dropped")
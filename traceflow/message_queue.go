@@ -8,51 +8,67 @@ import (
 	"github.com/streadway/amqp"
 	"github.com/wendall-robinson/flowmaster/traceflow/internal/kafkacarrier"
 	"github.com/wendall-robinson/flowmaster/traceflow/internal/rabbitmqcarrier"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 )
 
 // PropagateNats injects the trace context into NATS headers.
 func PropagateNats(ctx context.Context, headers nats.Header) {
-	propagator := otel.GetTextMapPropagator()
-	carrier := propagation.HeaderCarrier(headers)
-	propagator.Inject(ctx, carrier)
+	defaultTelemetry.PropagateNats(ctx, headers)
 }
 
 // PropagateKafka injects the trace context into Kafka headers.
 func PropagateKafka(ctx context.Context, headers *[]kafka.Header) {
-	propagator := otel.GetTextMapPropagator()
-	carrier := kafkacarrier.New(headers)
-	propagator.Inject(ctx, carrier)
+	defaultTelemetry.PropagateKafka(ctx, headers)
 }
 
 // PropagateRabbitMQ injects the trace context into RabbitMQ headers.
 func PropagateRabbitMQ(ctx context.Context, headers amqp.Table) {
-	propagator := otel.GetTextMapPropagator()
-	carrier := rabbitmqcarrier.New(headers)
-	propagator.Inject(ctx, carrier)
+	defaultTelemetry.PropagateRabbitMQ(ctx, headers)
 }
 
 // ExtractNats extracts the trace context from NATS headers.
 func ExtractNats(ctx context.Context, headers nats.Header) context.Context {
-	propagator := otel.GetTextMapPropagator()
-	carrier := propagation.HeaderCarrier(headers)
-
-	return propagator.Extract(ctx, carrier)
+	return defaultTelemetry.ExtractNats(ctx, headers)
 }
 
 // ExtractKafka extracts the trace context from Kafka headers.
 func ExtractKafka(ctx context.Context, headers []kafka.Header) context.Context {
-	propagator := otel.GetTextMapPropagator()
-	carrier := kafkacarrier.New(&headers)
-
-	return propagator.Extract(ctx, carrier)
+	return defaultTelemetry.ExtractKafka(ctx, headers)
 }
 
 // ExtractRabbitMQ extracts the trace context from RabbitMQ headers.
 func ExtractRabbitMQ(ctx context.Context, headers amqp.Table) context.Context {
-	propagator := otel.GetTextMapPropagator()
-	carrier := rabbitmqcarrier.New(headers)
+	return defaultTelemetry.ExtractRabbitMQ(ctx, headers)
+}
+
+// PropagateNats injects the trace context into NATS headers with the Telemetry's propagator.
+func (tel *Telemetry) PropagateNats(ctx context.Context, headers nats.Header) {
+	tel.Propagator().Inject(ctx, propagation.HeaderCarrier(headers))
+}
+
+// PropagateKafka injects the trace context into Kafka headers with the Telemetry's propagator.
+func (tel *Telemetry) PropagateKafka(ctx context.Context, headers *[]kafka.Header) {
+	tel.Propagator().Inject(ctx, kafkacarrier.New(headers))
+}
+
+// PropagateRabbitMQ injects the trace context into RabbitMQ headers with the Telemetry's
+// propagator.
+func (tel *Telemetry) PropagateRabbitMQ(ctx context.Context, headers amqp.Table) {
+	tel.Propagator().Inject(ctx, rabbitmqcarrier.New(headers))
+}
+
+// ExtractNats extracts the trace context from NATS headers with the Telemetry's propagator.
+func (tel *Telemetry) ExtractNats(ctx context.Context, headers nats.Header) context.Context {
+	return tel.Propagator().Extract(ctx, propagation.HeaderCarrier(headers))
+}
+
+// ExtractKafka extracts the trace context from Kafka headers with the Telemetry's propagator.
+func (tel *Telemetry) ExtractKafka(ctx context.Context, headers []kafka.Header) context.Context {
+	return tel.Propagator().Extract(ctx, kafkacarrier.New(&headers))
+}
 
-	return propagator.Extract(ctx, carrier)
+// ExtractRabbitMQ extracts the trace context from RabbitMQ headers with the Telemetry's
+// propagator.
+func (tel *Telemetry) ExtractRabbitMQ(ctx context.Context, headers amqp.Table) context.Context {
+	return tel.Propagator().Extract(ctx, rabbitmqcarrier.New(headers))
 }
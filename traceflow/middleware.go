@@ -0,0 +1,186 @@
+package traceflow
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// MiddlewareOption defines a functional option for customizing the HTTP middleware.
+type MiddlewareOption func(*middlewareConfig)
+
+// middlewareConfig holds the configuration for the HTTP middleware.
+type middlewareConfig struct {
+	namer        func(*http.Request) string
+	traceOptions []Option
+}
+
+// WithSpanNamer overrides how the middleware names server spans. The returned name is
+// formatted as "<service>.<name>", as with Start. By default the ServeMux route pattern
+// is used, falling back to the request method when no pattern matched.
+func WithSpanNamer(namer func(*http.Request) string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.namer = namer
+	}
+}
+
+// WithTraceOptions applies the given trace options to every span created by the middleware.
+func WithTraceOptions(opts ...Option) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.traceOptions = append(c.traceOptions, opts...)
+	}
+}
+
+// Middleware returns net/http middleware that traces every request with a Server span.
+// The trace context is extracted from the incoming headers, the span is named after the
+// Go 1.22 ServeMux route pattern (r.Pattern) or a custom namer, and the response status
+// code and size are recorded when the handler returns. Responses with a 5xx status mark
+// the span as Error.
+//
+// The *Trace for the request is stored in the request context and can be retrieved by
+// handlers with FromContext.
+//
+// Example usage:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("GET /users/{id}", getUser)
+//
+//	http.ListenAndServe(":8080", traceflow.Middleware("user-service")(mux))
+//
+// Notes:
+//   - When the middleware wraps a ServeMux, the route pattern is only known after routing,
+//     so the span is renamed once the handler returns.
+func Middleware(service string, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	cfg := &middlewareConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Extract after the trace options, so that the propagator set by WithTelemetry is used
+			traceOpts := append(slices.Clone(cfg.traceOptions), WithHTTPContext(r))
+
+			trace := New(r.Context(), service, traceOpts...).
+				Server().
+				AddHTTPRequest(r).
+				Start(cfg.spanName(r))
+			defer trace.EndWithRecover()
+
+			rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			req := r.WithContext(ContextWithTrace(trace.GetContext(), trace))
+
+			next.ServeHTTP(rw, req)
+
+			// The ServeMux sets the pattern on the request it routes, which is our copy
+			if r.Pattern == "" && req.Pattern != "" && cfg.namer == nil {
+				trace.setName(cfg.spanName(req))
+			}
+
+			if req.Pattern != "" {
+				trace.AddAttribute(AddString("http.route", req.Pattern))
+			}
+
+			trace.AddHTTPResponse(rw.status, rw.written)
+			trace.setHTTPStatus(rw.status)
+		})
+	}
+}
+
+// spanName returns the span name for a request.
+func (c *middlewareConfig) spanName(r *http.Request) string {
+	if c.namer != nil {
+		return c.namer(r)
+	}
+
+	if r.Pattern == "" {
+		return r.Method
+	}
+
+	// Patterns registered without a method still get the request method as a prefix
+	if strings.HasPrefix(r.Pattern, "/") {
+		return r.Method + " " + r.Pattern
+	}
+
+	return r.Pattern
+}
+
+// setHTTPStatus sets the span status from an HTTP server response code.
+func (t *Trace) setHTTPStatus(statusCode int) {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		t.SetStatus(codes.Error, http.StatusText(statusCode))
+	case statusCode < http.StatusBadRequest:
+		t.SetSuccess(http.StatusText(statusCode))
+	}
+}
+
+// setName renames the active span.
+func (t *Trace) setName(name string) {
+	if span := t.currentSpan(); span != nil {
+		span.SetName(t.service + "." + name)
+	}
+}
+
+// responseRecorder wraps an http.ResponseWriter to capture the status code and the
+// number of body bytes written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+// WriteHeader records the status code before delegating to the wrapped writer.
+func (rw *responseRecorder) WriteHeader(statusCode int) {
+	if !rw.wroteHeader {
+		rw.status = statusCode
+		rw.wroteHeader = true
+	}
+
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the number of bytes written before delegating to the wrapped writer.
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.written += int64(n)
+
+	return n, err
+}
+
+// Flush implements http.Flusher when the wrapped writer supports it.
+func (rw *responseRecorder) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		rw.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the wrapped writer supports it, so handlers behind
+// the middleware can take over the connection, for example to upgrade it to a WebSocket.
+func (rw *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped writer for use with http.ResponseController.
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Ensure responseRecorder keeps http.Flusher and http.Hijacker support
+var (
+	_ http.Flusher  = (*responseRecorder)(nil)
+	_ http.Hijacker = (*responseRecorder)(nil)
+)
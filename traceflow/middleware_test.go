@@ -0,0 +1,164 @@
+package traceflow
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// TestMiddlewareRoutePattern tests that spans are named from the ServeMux pattern and
+// record the response status and size.
+func TestMiddlewareRoutePattern(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		trace, ok := FromContext(r.Context())
+		if !ok {
+			t.Error("Expected the trace to be stored in the request context")
+		} else {
+			trace.AddUser(r.PathValue("id"), "alice")
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	rec := httptest.NewRecorder()
+	Middleware("test-service")(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	span := spans[0]
+	assert.Equal(t, "test-service.GET /users/{id}", span.Name)
+	assert.Equal(t, oteltrace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, codes.Ok, span.Status.Code)
+
+	expected := []attribute.KeyValue{
+		attribute.String("http.method", http.MethodGet),
+		attribute.String("http.route", "GET /users/{id}"),
+		attribute.Int("http.status_code", http.StatusCreated),
+		attribute.Int64("http.content_length", 5),
+		attribute.String("user.id", "42"),
+	}
+
+	for _, attr := range expected {
+		if v, ok := findAttribute(span, attr.Key); !ok || v != attr.Value {
+			t.Errorf("Expected attribute %v, got %v", attr, v)
+		}
+	}
+}
+
+// TestMiddlewareServerError tests that 5xx responses mark the span as Error and that
+// an incoming trace context is continued.
+func TestMiddlewareServerError(t *testing.T) {
+	exporter := useRecordingProvider(t)
+	useTraceContextPropagator(t)
+
+	handler := Middleware("test-service", WithSpanNamer(func(*http.Request) string {
+		return "custom"
+	}))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "broken", http.StatusBadGateway)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/anything", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	assert.Equal(t, "test-service.custom", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+}
+
+// TestMiddlewareWithoutPattern tests the fallback span name and the default status code.
+func TestMiddlewareWithoutPattern(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	handler := Middleware("test-service")(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/", nil).WithContext(context.Background()))
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	assert.Equal(t, "test-service.DELETE", spans[0].Name)
+
+	if v, _ := findAttribute(spans[0], "http.status_code"); v.AsInt64() != http.StatusOK {
+		t.Errorf("Expected implicit 200 status code, got %v", v)
+	}
+}
+
+// useTraceContextPropagator installs the W3C Trace Context propagator for the duration of the test.
+func useTraceContextPropagator(t *testing.T) {
+	t.Helper()
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(previous)
+	})
+}
+
+// TestMiddlewareHijack tests that handlers can hijack the connection through the
+// middleware, and get http.ErrNotSupported when the server's writer cannot be hijacked.
+func TestMiddlewareHijack(t *testing.T) {
+	useRecordingProvider(t)
+
+	hijacked := make(chan error, 1)
+	handler := Middleware("test-service")(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		hijacked <- err
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = buf.Flush()
+	}))
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+
+	assert.NoError(t, <-hijacked)
+	assert.Equal(t, "hijacked", string(body))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.ErrorIs(t, <-hijacked, http.ErrNotSupported)
+}
@@ -0,0 +1,318 @@
+package traceflow
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// OTLPOption defines a functional option for configuring the OTLP exporters created by
+// WithOLTP and WithOTLPHTTP.
+type OTLPOption func(*otlpConfig)
+
+// otlpConfig holds the transport settings shared by the OTLP gRPC and HTTP exporters.
+type otlpConfig struct {
+	caFile      string
+	certFile    string
+	keyFile     string
+	tlsConfig   *tls.Config
+	headers     map[string]string
+	compression bool
+	timeout     time.Duration
+	retry       *otlpRetry
+	urlPath     string
+	filter      SpanFilter
+	buffer      *diskBuffer
+}
+
+// otlpRetry holds the retry backoff settings for an OTLP exporter.
+type otlpRetry struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	maxElapsedTime  time.Duration
+}
+
+// WithOTLPCACertificate enables TLS and verifies the collector's certificate against the
+// PEM encoded CA certificate in caFile instead of the system roots.
+func WithOTLPCACertificate(caFile string) OTLPOption {
+	return func(c *otlpConfig) {
+		c.caFile = caFile
+	}
+}
+
+// WithOTLPClientCertificate enables mutual TLS, presenting the PEM encoded certificate and
+// key in certFile and keyFile to the collector.
+func WithOTLPClientCertificate(certFile, keyFile string) OTLPOption {
+	return func(c *otlpConfig) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// WithOTLPTLSConfig enables TLS using the given configuration. Certificates set with
+// WithOTLPCACertificate and WithOTLPClientCertificate are added to a copy of it.
+func WithOTLPTLSConfig(cfg *tls.Config) OTLPOption {
+	return func(c *otlpConfig) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithOTLPHeaders sends the given headers, such as authentication tokens, with every export.
+func WithOTLPHeaders(headers map[string]string) OTLPOption {
+	return func(c *otlpConfig) {
+		if c.headers == nil {
+			c.headers = make(map[string]string, len(headers))
+		}
+
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// WithOTLPCompression compresses exported spans with gzip.
+func WithOTLPCompression() OTLPOption {
+	return func(c *otlpConfig) {
+		c.compression = true
+	}
+}
+
+// WithOTLPTimeout sets the maximum time allowed for each export request.
+func WithOTLPTimeout(timeout time.Duration) OTLPOption {
+	return func(c *otlpConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithOTLPRetry configures the exponential backoff used to retry failed exports. Retries
+// start after initialInterval, back off up to maxInterval between attempts, and stop once
+// maxElapsedTime has passed.
+func WithOTLPRetry(initialInterval, maxInterval, maxElapsedTime time.Duration) OTLPOption {
+	return func(c *otlpConfig) {
+		c.retry = &otlpRetry{
+			initialInterval: initialInterval,
+			maxInterval:     maxInterval,
+			maxElapsedTime:  maxElapsedTime,
+		}
+	}
+}
+
+// WithOTLPURLPath overrides the URL path spans are posted to by the HTTP exporter. The
+// default is "/v1/traces".
+func WithOTLPURLPath(path string) OTLPOption {
+	return func(c *otlpConfig) {
+		c.urlPath = path
+	}
+}
+
+// WithOTLPFilter only sends spans for which filter returns true to the collector.
+func WithOTLPFilter(filter SpanFilter) OTLPOption {
+	return func(c *otlpConfig) {
+		c.filter = filter
+	}
+}
+
+// WithOTLPDiskBuffer keeps spans in a bounded queue in dir while the collector is
+// unreachable and sends them once it is back. Dropped spans are reported to the
+// OpenTelemetry error handler. See WithDiskBuffer.
+func WithOTLPDiskBuffer(dir string, opts ...BufferOption) OTLPOption {
+	return func(c *otlpConfig) {
+		c.buffer = &diskBuffer{dir: dir, opts: opts}
+	}
+}
+
+// exporterOptions returns the options used to register the OTLP exporter.
+func (c *otlpConfig) exporterOptions() []ExporterOption {
+	opts := []ExporterOption{WithSpanFilter(c.filter)}
+	if c.buffer != nil {
+		opts = append(opts, WithDiskBuffer(c.buffer.dir, c.buffer.opts...))
+	}
+
+	return opts
+}
+
+// WithOTLPHTTP adds an OTLP exporter that sends traces to an OpenTelemetry collector using
+// HTTP/protobuf. The endpoint is either a host and port, such as "otel:4318", or a full URL
+// such as "https://otel.example.com/v1/traces".
+//
+// TLS is used when the endpoint URL uses the https scheme or any TLS option is set;
+// otherwise spans are sent over plain HTTP. Setting a TLS option for an endpoint URL with
+// the http scheme is an error, since the scheme would disable TLS.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithOTLPHTTP("https://otel.example.com",
+//	        traceflow.WithOTLPHeaders(map[string]string{"Authorization": "Bearer " + token}),
+//	        traceflow.WithOTLPCompression(),
+//	    ),
+//	)
+func WithOTLPHTTP(endpoint string, opts ...OTLPOption) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.logger.Println("Using OTLP HTTP exporter")
+
+		exp, err := newOTLPHTTPExporter(tb.ctx, endpoint, opts)
+		if err != nil {
+			tb.addError(errors.ErrTraceExporterCreation, err)
+			return
+		}
+
+		tb.addExporter(exp, newOTLPConfig(opts).exporterOptions()...)
+	}
+}
+
+// newOTLPGRPCExporter creates an OTLP gRPC exporter for the target.
+func newOTLPGRPCExporter(ctx context.Context, target string, opts []OTLPOption) (sdktrace.SpanExporter, error) {
+	cfg := newOTLPConfig(opts)
+
+	tlsConfig, err := cfg.buildTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	exporterOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(target)}
+
+	if tlsConfig != nil {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+	}
+
+	if len(cfg.headers) > 0 {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithHeaders(cfg.headers))
+	}
+
+	if cfg.compression {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithCompressor("gzip"))
+	}
+
+	if cfg.timeout > 0 {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithTimeout(cfg.timeout))
+	}
+
+	if cfg.retry != nil {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
+			Enabled:         true,
+			InitialInterval: cfg.retry.initialInterval,
+			MaxInterval:     cfg.retry.maxInterval,
+			MaxElapsedTime:  cfg.retry.maxElapsedTime,
+		}))
+	}
+
+	return otlptracegrpc.New(ctx, exporterOpts...)
+}
+
+// newOTLPHTTPExporter creates an OTLP HTTP exporter for the endpoint.
+func newOTLPHTTPExporter(ctx context.Context, endpoint string, opts []OTLPOption) (sdktrace.SpanExporter, error) {
+	cfg := newOTLPConfig(opts)
+
+	tlsConfig, err := cfg.buildTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// The exporter ignores the TLS configuration for an http:// URL
+	if tlsConfig != nil && strings.HasPrefix(strings.ToLower(endpoint), "http://") {
+		return nil, fmt.Errorf("%w: %s", errors.ErrInsecureEndpoint, endpoint)
+	}
+
+	var exporterOpts []otlptracehttp.Option
+
+	if strings.Contains(endpoint, "://") {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpointURL(endpoint))
+	} else {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(endpoint))
+	}
+
+	switch {
+	case tlsConfig != nil:
+		exporterOpts = append(exporterOpts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	case !strings.HasPrefix(endpoint, "https://"):
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+
+	if cfg.urlPath != "" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithURLPath(cfg.urlPath))
+	}
+
+	if len(cfg.headers) > 0 {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithHeaders(cfg.headers))
+	}
+
+	if cfg.compression {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+
+	if cfg.timeout > 0 {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithTimeout(cfg.timeout))
+	}
+
+	if cfg.retry != nil {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         true,
+			InitialInterval: cfg.retry.initialInterval,
+			MaxInterval:     cfg.retry.maxInterval,
+			MaxElapsedTime:  cfg.retry.maxElapsedTime,
+		}))
+	}
+
+	return otlptracehttp.New(ctx, exporterOpts...)
+}
+
+// newOTLPConfig applies the options to an empty configuration.
+func newOTLPConfig(opts []OTLPOption) *otlpConfig {
+	cfg := &otlpConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// buildTLSConfig returns the TLS configuration for the exporter, or nil if TLS has not
+// been configured.
+func (c *otlpConfig) buildTLSConfig() (*tls.Config, error) {
+	if c.tlsConfig == nil && c.caFile == "" && c.certFile == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.tlsConfig != nil {
+		cfg = c.tlsConfig.Clone()
+	}
+
+	if c.caFile != "" {
+		pem, err := os.ReadFile(c.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: %s", errors.ErrNoCertificates, c.caFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if c.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,336 @@
+package traceflow
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+// testCertificate is a self-signed certificate written to disk for TLS tests.
+type testCertificate struct {
+	certFile string
+	keyFile  string
+	cert     tls.Certificate
+	pool     *x509.CertPool
+}
+
+// newTestCertificate generates a self-signed certificate valid for 127.0.0.1 that can be
+// used both as a server certificate and as a client certificate.
+func newTestCertificate(t *testing.T, name string) testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	return testCertificate{certFile: certFile, keyFile: keyFile, cert: cert, pool: pool}
+}
+
+// testSpans returns a single finished span snapshot to export.
+func testSpans() []sdktrace.ReadOnlySpan {
+	return tracetest.SpanStubs{{Name: "test-span"}}.Snapshots()
+}
+
+// TestOTLPHTTPMutualTLS tests the HTTP exporter against a stand-in collector that requires
+// client certificates, custom headers, and gzip compression.
+func TestOTLPHTTPMutualTLS(t *testing.T) {
+	serverCert := newTestCertificate(t, "server")
+	clientCert := newTestCertificate(t, "client")
+
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+	)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCert.pool,
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	exporter, err := newOTLPHTTPExporter(context.Background(), server.URL, []OTLPOption{
+		WithOTLPCACertificate(serverCert.certFile),
+		WithOTLPClientCertificate(clientCert.certFile, clientCert.keyFile),
+		WithOTLPHeaders(map[string]string{"Authorization": "Bearer secret"}),
+		WithOTLPCompression(),
+		WithOTLPTimeout(5 * time.Second),
+	})
+	require.NoError(t, err)
+
+	defer exporter.Shutdown(context.Background())
+
+	require.NoError(t, exporter.ExportSpans(context.Background(), testSpans()))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "/v1/traces", requests[0].URL.Path)
+		assert.Equal(t, "Bearer secret", requests[0].Header.Get("Authorization"))
+		assert.Equal(t, "gzip", requests[0].Header.Get("Content-Encoding"))
+	}
+}
+
+// TestOTLPHTTPRequiresClientCertificate tests that exports fail when mTLS is required but
+// no client certificate is configured.
+func TestOTLPHTTPRequiresClientCertificate(t *testing.T) {
+	serverCert := newTestCertificate(t, "server")
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	exporter, err := newOTLPHTTPExporter(context.Background(), server.URL, []OTLPOption{
+		WithOTLPCACertificate(serverCert.certFile),
+		WithOTLPTimeout(time.Second),
+	})
+	require.NoError(t, err)
+
+	defer exporter.Shutdown(context.Background())
+
+	assert.Error(t, exporter.ExportSpans(context.Background(), testSpans()))
+}
+
+// TestOTLPHTTPRetry tests that failed exports are retried with backoff.
+func TestOTLPHTTPRetry(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exporter, err := newOTLPHTTPExporter(context.Background(), server.Listener.Addr().String(), []OTLPOption{
+		WithOTLPRetry(10*time.Millisecond, 50*time.Millisecond, 5*time.Second),
+		WithOTLPURLPath("/custom/traces"),
+	})
+	require.NoError(t, err)
+
+	defer exporter.Shutdown(context.Background())
+
+	require.NoError(t, exporter.ExportSpans(context.Background(), testSpans()))
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+// TestWithOTLPHTTP tests that Init exports spans through the HTTP exporter.
+func TestWithOTLPHTTP(t *testing.T) {
+	var received atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			received.Add(1)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, shutdown, err := Init(context.Background(), "test-service", WithSilentLogger(), WithOTLPHTTP(server.URL))
+	require.NoError(t, err)
+
+	New(ctx, "test-service").Start("exported").End()
+	shutdown(ctx)
+
+	assert.Equal(t, int32(1), received.Load())
+}
+
+// testTraceCollector is a stand-in OTLP gRPC collector that records incoming metadata.
+type testTraceCollector struct {
+	collectortrace.UnimplementedTraceServiceServer
+	mu          sync.Mutex
+	metadata    []metadata.MD
+	compression []string
+}
+
+func (c *testTraceCollector) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (c *testTraceCollector) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		c.mu.Lock()
+		c.compression = append(c.compression, header.Compression)
+		c.mu.Unlock()
+	}
+}
+
+func (c *testTraceCollector) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (c *testTraceCollector) HandleConn(context.Context, stats.ConnStats) {}
+
+func (c *testTraceCollector) Export(
+	ctx context.Context,
+	_ *collectortrace.ExportTraceServiceRequest,
+) (*collectortrace.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	c.mu.Lock()
+	c.metadata = append(c.metadata, md)
+	c.mu.Unlock()
+
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+// TestOTLPGRPCTLS tests the gRPC exporter against a TLS stand-in collector.
+func TestOTLPGRPCTLS(t *testing.T) {
+	serverCert := newTestCertificate(t, "server")
+	collector := &testTraceCollector{}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewServerTLSFromCert(&serverCert.cert)),
+		grpc.StatsHandler(collector),
+	)
+	collectortrace.RegisterTraceServiceServer(server, collector)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	exporter, err := newOTLPGRPCExporter(context.Background(), listener.Addr().String(), []OTLPOption{
+		WithOTLPCACertificate(serverCert.certFile),
+		WithOTLPHeaders(map[string]string{"authorization": "Bearer secret"}),
+		WithOTLPCompression(),
+		WithOTLPTimeout(5 * time.Second),
+		WithOTLPRetry(10*time.Millisecond, 50*time.Millisecond, time.Second),
+	})
+	require.NoError(t, err)
+
+	defer exporter.Shutdown(context.Background())
+
+	require.NoError(t, exporter.ExportSpans(context.Background(), testSpans()))
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if assert.Len(t, collector.metadata, 1) {
+		assert.Equal(t, []string{"Bearer secret"}, collector.metadata[0].Get("authorization"))
+	}
+
+	assert.Equal(t, []string{"gzip"}, collector.compression)
+}
+
+// TestOTLPHTTPInsecureEndpointWithTLS tests that TLS options are rejected for an http://
+// endpoint instead of being ignored.
+func TestOTLPHTTPInsecureEndpointWithTLS(t *testing.T) {
+	_, err := newOTLPHTTPExporter(context.Background(), "http://localhost:4318", []OTLPOption{
+		WithOTLPTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}),
+	})
+	assert.ErrorIs(t, err, errors.ErrInsecureEndpoint)
+
+	_, err = newOTLPHTTPExporter(context.Background(), "http://localhost:4318", nil)
+	assert.NoError(t, err, "Expected plain HTTP without TLS options to be accepted")
+
+	_, _, err = Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithOTLPHTTP("HTTP://localhost:4318", WithOTLPTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})),
+	)
+
+	assert.ErrorIs(t, err, errors.ErrTraceExporterCreation)
+	assert.ErrorIs(t, err, errors.ErrInsecureEndpoint)
+}
+
+// TestOTLPInvalidCACertificate tests that unusable CA files are reported.
+func TestOTLPInvalidCACertificate(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+	_, err := newOTLPGRPCExporter(context.Background(), "localhost:4317", []OTLPOption{WithOTLPCACertificate(caFile)})
+	assert.ErrorIs(t, err, errors.ErrNoCertificates)
+
+	_, err = newOTLPHTTPExporter(context.Background(), "localhost:4318", []OTLPOption{
+		WithOTLPClientCertificate(filepath.Join(t.TempDir(), "missing.pem"), ""),
+	})
+	assert.Error(t, err)
+}
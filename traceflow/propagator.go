@@ -0,0 +1,96 @@
+package traceflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// defaultPropagators returns the propagators installed when none are configured.
+func defaultPropagators() []propagation.TextMapPropagator {
+	return []propagation.TextMapPropagator{
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	}
+}
+
+// propagatorByName returns the propagator registered under the given name, as used by
+// the OTEL_PROPAGATORS environment variable: "tracecontext", "baggage", "b3", "b3multi",
+// "jaeger" or "none". The name "none" returns nil.
+func propagatorByName(name string) (propagation.TextMapPropagator, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "tracecontext":
+		return propagation.TraceContext{}, nil
+	case "baggage":
+		return propagation.Baggage{}, nil
+	case "b3":
+		return B3Propagator{}, nil
+	case "b3multi":
+		return B3Propagator{MultipleHeaders: true}, nil
+	case "jaeger":
+		return JaegerPropagator{}, nil
+	case "none":
+		return nil, nil //nolint:nilnil
+	default:
+		return nil, fmt.Errorf("%w: %q", errors.ErrUnknownPropagator, name)
+	}
+}
+
+// WithPropagators sets the propagators of the text map propagator, which Init installs
+// globally, replacing the default TraceContext and Baggage propagators. Each argument is either a
+// propagation.TextMapPropagator or the name of a built-in propagator: "tracecontext",
+// "baggage", "b3" (single header), "b3multi" (multiple headers), "jaeger" or "none".
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithPropagators("tracecontext", "baggage", "b3multi"),
+//	)
+func WithPropagators(propagators ...any) InitOption {
+	return func(tb *TelemetryBuilder) {
+		resolved := make([]propagation.TextMapPropagator, 0, len(propagators))
+
+		for _, p := range propagators {
+			switch p := p.(type) {
+			case propagation.TextMapPropagator:
+				resolved = append(resolved, p)
+			case string:
+				propagator, err := propagatorByName(p)
+				if err != nil {
+					tb.addError(errors.ErrUnknownPropagator, fmt.Errorf("%q", p))
+					return
+				}
+
+				if propagator != nil {
+					resolved = append(resolved, propagator)
+				}
+			default:
+				tb.addError(errors.ErrUnknownPropagator, fmt.Errorf("%T", p))
+				return
+			}
+		}
+
+		tb.propagators = resolved
+	}
+}
+
+// propagatorsByName resolves a list of propagator names. An empty, non-nil slice is
+// returned when only "none" is given, which disables propagation.
+func propagatorsByName(names ...string) ([]propagation.TextMapPropagator, error) {
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+
+	for _, name := range names {
+		propagator, err := propagatorByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if propagator != nil {
+			propagators = append(propagators, propagator)
+		}
+	}
+
+	return propagators, nil
+}
@@ -0,0 +1,174 @@
+package traceflow
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// B3 header names. Lowercase names are used so they survive gRPC metadata, which lowercases
+// every key; extraction matches them case-insensitively.
+const (
+	b3SingleHeader = "b3"
+	b3TraceID      = "x-b3-traceid"
+	b3SpanID       = "x-b3-spanid"
+	b3ParentSpanID = "x-b3-parentspanid"
+	b3Sampled      = "x-b3-sampled"
+	b3Flags        = "x-b3-flags"
+)
+
+// B3Propagator propagates trace context in the Zipkin B3 format. It extracts both the
+// single "b3" header and the multiple "X-B3-*" headers, and injects the single header
+// unless MultipleHeaders is set.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithPropagators(traceflow.B3Propagator{MultipleHeaders: true}),
+//	)
+type B3Propagator struct {
+	// MultipleHeaders injects the X-B3-TraceId, X-B3-SpanId and X-B3-Sampled headers
+	// instead of the single b3 header.
+	MultipleHeaders bool
+}
+
+// Inject sets the B3 headers for the span context in ctx on the carrier.
+func (p B3Propagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+
+	if p.MultipleHeaders {
+		carrier.Set(b3TraceID, sc.TraceID().String())
+		carrier.Set(b3SpanID, sc.SpanID().String())
+		carrier.Set(b3Sampled, sampled)
+
+		return
+	}
+
+	carrier.Set(b3SingleHeader, sc.TraceID().String()+"-"+sc.SpanID().String()+"-"+sampled)
+}
+
+// Extract returns a copy of ctx holding the remote span context read from the carrier's
+// B3 headers. The single header takes precedence over the multiple headers.
+func (p B3Propagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	sc, ok := extractB3Single(carrierGet(carrier, b3SingleHeader))
+	if !ok {
+		sc, ok = extractB3Multiple(carrier)
+	}
+
+	if !ok {
+		return ctx
+	}
+
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the headers set by Inject.
+func (p B3Propagator) Fields() []string {
+	if p.MultipleHeaders {
+		return []string{b3TraceID, b3SpanID, b3Sampled}
+	}
+
+	return []string{b3SingleHeader}
+}
+
+// extractB3Single parses a single b3 header: {TraceId}-{SpanId}[-{Sampled}[-{ParentSpanId}]].
+// A header carrying only a sampling decision does not contain a span context.
+func extractB3Single(value string) (trace.SpanContext, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}, false
+	}
+
+	sampled := ""
+	if len(parts) > 2 {
+		sampled = parts[2]
+	}
+
+	if len(parts) == 4 {
+		if _, err := trace.SpanIDFromHex(parts[3]); err != nil {
+			return trace.SpanContext{}, false
+		}
+	}
+
+	return newB3SpanContext(parts[0], parts[1], sampled, "")
+}
+
+// extractB3Multiple reads the X-B3-* headers.
+func extractB3Multiple(carrier propagation.TextMapCarrier) (trace.SpanContext, bool) {
+	return newB3SpanContext(
+		carrierGet(carrier, b3TraceID),
+		carrierGet(carrier, b3SpanID),
+		carrierGet(carrier, b3Sampled),
+		carrierGet(carrier, b3Flags),
+	)
+}
+
+// newB3SpanContext builds a remote span context from B3 fields. 64-bit trace IDs are
+// left-padded to 128 bits. The debug flag, or a sampling state of "d", means sampled.
+func newB3SpanContext(traceID, spanID, sampled, flags string) (trace.SpanContext, bool) {
+	if len(traceID) == 16 { //nolint:mnd
+		traceID = strings.Repeat("0", 16) + traceID //nolint:mnd
+	}
+
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	var traceFlags trace.TraceFlags
+
+	switch strings.ToLower(sampled) {
+	case "1", "true", "d":
+		traceFlags = trace.FlagsSampled
+	case "", "0", "false":
+	default:
+		return trace.SpanContext{}, false
+	}
+
+	if flags == "1" {
+		traceFlags = trace.FlagsSampled
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: traceFlags,
+		Remote:     true,
+	})
+
+	return sc, sc.IsValid()
+}
+
+// carrierGet returns the carrier value for key, falling back to a case-insensitive match
+// for carriers such as Kafka and AMQP headers that compare keys exactly.
+func carrierGet(carrier propagation.TextMapCarrier, key string) string {
+	if value := carrier.Get(key); value != "" {
+		return value
+	}
+
+	for _, k := range carrier.Keys() {
+		if strings.EqualFold(k, key) {
+			return carrier.Get(k)
+		}
+	}
+
+	return ""
+}
+
+// Ensure B3Propagator implements propagation.TextMapPropagator
+var _ propagation.TextMapPropagator = B3Propagator{}
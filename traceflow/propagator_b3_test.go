@@ -0,0 +1,116 @@
+package traceflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestB3Inject tests the single and multiple header formats.
+func TestB3Inject(t *testing.T) {
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext(t))
+
+	single := propagation.MapCarrier{}
+	B3Propagator{}.Inject(ctx, single)
+	assert.Equal(t, propagation.MapCarrier{
+		"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	}, single)
+
+	multi := propagation.MapCarrier{}
+	B3Propagator{MultipleHeaders: true}.Inject(ctx, multi)
+	assert.Equal(t, propagation.MapCarrier{
+		"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"x-b3-spanid":  "00f067aa0ba902b7",
+		"x-b3-sampled": "1",
+	}, multi)
+
+	empty := propagation.MapCarrier{}
+	B3Propagator{}.Inject(context.Background(), empty)
+	assert.Empty(t, empty)
+}
+
+// TestB3Extract tests parsing of the single and multiple header formats.
+func TestB3Extract(t *testing.T) {
+	tests := []struct {
+		name    string
+		carrier propagation.MapCarrier
+		traceID string
+		sampled bool
+		valid   bool
+	}{
+		{
+			name:    "single",
+			carrier: propagation.MapCarrier{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			sampled: true,
+			valid:   true,
+		},
+		{
+			name: "single with parent and 64-bit trace ID",
+			carrier: propagation.MapCarrier{
+				"b3": "a3ce929d0e0e4736-00f067aa0ba902b7-0-05e3ac9a4f6e3b90",
+			},
+			traceID: "0000000000000000a3ce929d0e0e4736",
+			valid:   true,
+		},
+		{
+			name:    "single debug",
+			carrier: propagation.MapCarrier{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-d"},
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			sampled: true,
+			valid:   true,
+		},
+		{
+			name: "single takes precedence",
+			carrier: propagation.MapCarrier{
+				"b3":           "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+				"x-b3-traceid": "0000000000000000a3ce929d0e0e4736",
+				"x-b3-spanid":  "00f067aa0ba902b7",
+			},
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			sampled: true,
+			valid:   true,
+		},
+		{
+			name: "multiple with canonical header names",
+			carrier: propagation.MapCarrier{
+				"X-B3-TraceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"X-B3-SpanId":  "00f067aa0ba902b7",
+				"X-B3-Flags":   "1",
+			},
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			sampled: true,
+			valid:   true,
+		},
+		{
+			name:    "sampling decision only",
+			carrier: propagation.MapCarrier{"b3": "0"},
+		},
+		{
+			name:    "invalid trace ID",
+			carrier: propagation.MapCarrier{"b3": "xyz-00f067aa0ba902b7-1"},
+		},
+		{
+			name:    "invalid sampling state",
+			carrier: propagation.MapCarrier{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := trace.SpanContextFromContext(B3Propagator{}.Extract(context.Background(), tt.carrier))
+
+			assert.Equal(t, tt.valid, sc.IsValid())
+
+			if tt.valid {
+				assert.Equal(t, tt.traceID, sc.TraceID().String())
+				assert.Equal(t, "00f067aa0ba902b7", sc.SpanID().String())
+				assert.Equal(t, tt.sampled, sc.IsSampled())
+				assert.True(t, sc.IsRemote())
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package traceflow
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// jaegerHeader is the header used by Jaeger clients to propagate trace context.
+const jaegerHeader = "uber-trace-id"
+
+// Jaeger flag bits carried in the uber-trace-id header.
+const (
+	jaegerFlagSampled = 0x01
+	jaegerFlagDebug   = 0x02
+)
+
+// JaegerPropagator propagates trace context in the Jaeger uber-trace-id format:
+// {trace-id}:{span-id}:{parent-span-id}:{flags}.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithPropagators(traceflow.JaegerPropagator{}, "baggage"),
+//	)
+type JaegerPropagator struct{}
+
+// Inject sets the uber-trace-id header for the span context in ctx on the carrier.
+func (JaegerPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	flags := 0
+	if sc.IsSampled() {
+		flags = jaegerFlagSampled
+	}
+
+	carrier.Set(jaegerHeader, fmt.Sprintf("%s:%s:0:%d", sc.TraceID(), sc.SpanID(), flags))
+}
+
+// Extract returns a copy of ctx holding the remote span context read from the carrier's
+// uber-trace-id header.
+func (JaegerPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	sc, ok := extractJaeger(carrierGet(carrier, jaegerHeader))
+	if !ok {
+		return ctx
+	}
+
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the header set by Inject.
+func (JaegerPropagator) Fields() []string {
+	return []string{jaegerHeader}
+}
+
+// extractJaeger parses an uber-trace-id value. Jaeger clients may drop leading zeros from
+// the IDs and URL-encode the colons.
+func extractJaeger(value string) (trace.SpanContext, bool) {
+	if decoded, err := url.QueryUnescape(value); err == nil {
+		value = decoded
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 { //nolint:mnd
+		return trace.SpanContext{}, false
+	}
+
+	traceID, spanID := parts[0], parts[1]
+	if len(traceID) > 32 || len(spanID) > 16 { //nolint:mnd
+		return trace.SpanContext{}, false
+	}
+
+	tid, err := trace.TraceIDFromHex(strings.Repeat("0", 32-len(traceID)) + traceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	sid, err := trace.SpanIDFromHex(strings.Repeat("0", 16-len(spanID)) + spanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	var traceFlags trace.TraceFlags
+	if flags&(jaegerFlagSampled|jaegerFlagDebug) != 0 {
+		traceFlags = trace.FlagsSampled
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: traceFlags,
+		Remote:     true,
+	})
+
+	return sc, sc.IsValid()
+}
+
+// Ensure JaegerPropagator implements propagation.TextMapPropagator
+var _ propagation.TextMapPropagator = JaegerPropagator{}
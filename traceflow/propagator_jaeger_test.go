@@ -0,0 +1,75 @@
+package traceflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestJaegerInject tests the uber-trace-id format.
+func TestJaegerInject(t *testing.T) {
+	carrier := propagation.MapCarrier{}
+	JaegerPropagator{}.Inject(trace.ContextWithSpanContext(context.Background(), testSpanContext(t)), carrier)
+
+	assert.Equal(t, propagation.MapCarrier{
+		"uber-trace-id": "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1",
+	}, carrier)
+}
+
+// TestJaegerExtract tests parsing of uber-trace-id values.
+func TestJaegerExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		traceID string
+		spanID  string
+		sampled bool
+		valid   bool
+	}{
+		{
+			name:    "full",
+			value:   "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1",
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			spanID:  "00f067aa0ba902b7",
+			sampled: true,
+			valid:   true,
+		},
+		{
+			name:    "short IDs",
+			value:   "a3ce929d0e0e4736:f067aa0ba902b7:05e3ac9a4f6e3b90:0",
+			traceID: "0000000000000000a3ce929d0e0e4736",
+			spanID:  "00f067aa0ba902b7",
+			valid:   true,
+		},
+		{
+			name:    "debug and url encoded",
+			value:   "4bf92f3577b34da6a3ce929d0e0e4736%3A00f067aa0ba902b7%3A0%3A2",
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			spanID:  "00f067aa0ba902b7",
+			sampled: true,
+			valid:   true,
+		},
+		{name: "missing fields", value: "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7"},
+		{name: "zero trace ID", value: "0:00f067aa0ba902b7:0:1"},
+		{name: "invalid flags", value: "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:z"},
+		{name: "trace ID too long", value: "14bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			carrier := propagation.MapCarrier{"uber-trace-id": tt.value}
+			sc := trace.SpanContextFromContext(JaegerPropagator{}.Extract(context.Background(), carrier))
+
+			assert.Equal(t, tt.valid, sc.IsValid())
+
+			if tt.valid {
+				assert.Equal(t, tt.traceID, sc.TraceID().String())
+				assert.Equal(t, tt.spanID, sc.SpanID().String())
+				assert.Equal(t, tt.sampled, sc.IsSampled())
+			}
+		})
+	}
+}
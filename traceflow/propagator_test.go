@@ -0,0 +1,164 @@
+package traceflow
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// testSpanContext returns a sampled span context with fixed IDs.
+func testSpanContext(t *testing.T) trace.SpanContext {
+	t.Helper()
+
+	tid, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+
+	sid, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	return trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: trace.FlagsSampled})
+}
+
+// usePropagator installs the propagator globally for the duration of the test.
+func usePropagator(t *testing.T, propagator propagation.TextMapPropagator) {
+	t.Helper()
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagator)
+
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+}
+
+// TestPropagatorRoundTrip tests that each propagator format survives injection into and
+// extraction from every carrier traceflow supports.
+func TestPropagatorRoundTrip(t *testing.T) {
+	propagators := map[string]propagation.TextMapPropagator{
+		"tracecontext": propagation.TraceContext{},
+		"b3 single":    B3Propagator{},
+		"b3 multi":     B3Propagator{MultipleHeaders: true},
+		"jaeger":       JaegerPropagator{},
+	}
+
+	carriers := map[string]func(ctx context.Context) context.Context{
+		"http": func(ctx context.Context) context.Context {
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			New(ctx, "test-service").InjectHTTPContext(req)
+
+			return New(context.Background(), "test-service").ExtractHTTPContext(req).GetContext()
+		},
+		"grpc": func(ctx context.Context) context.Context {
+			outgoing := New(ctx, "test-service").InjectGRPCContext(context.Background())
+			md, _ := metadata.FromOutgoingContext(outgoing)
+
+			return ExtractGRPCContext(metadata.NewIncomingContext(context.Background(), md))
+		},
+		"kafka": func(ctx context.Context) context.Context {
+			var headers []kafka.Header
+			PropagateKafka(ctx, &headers)
+
+			return ExtractKafka(context.Background(), headers)
+		},
+		"nats": func(ctx context.Context) context.Context {
+			headers := nats.Header{}
+			PropagateNats(ctx, headers)
+
+			return ExtractNats(context.Background(), headers)
+		},
+		"rabbitmq": func(ctx context.Context) context.Context {
+			headers := amqp.Table{}
+			PropagateRabbitMQ(ctx, headers)
+
+			return ExtractRabbitMQ(context.Background(), headers)
+		},
+	}
+
+	want := testSpanContext(t)
+	ctx := trace.ContextWithSpanContext(context.Background(), want)
+
+	for propagatorName, propagator := range propagators {
+		for carrierName, roundTrip := range carriers {
+			t.Run(propagatorName+"/"+carrierName, func(t *testing.T) {
+				usePropagator(t, propagator)
+
+				got := trace.SpanContextFromContext(roundTrip(ctx))
+
+				assert.True(t, got.IsRemote(), "Expected a remote span context")
+				assert.Equal(t, want.TraceID(), got.TraceID())
+				assert.Equal(t, want.SpanID(), got.SpanID())
+				assert.True(t, got.IsSampled())
+			})
+		}
+	}
+}
+
+// TestWithPropagators tests that names and implementations can be mixed.
+func TestWithPropagators(t *testing.T) {
+	tests := []struct {
+		name        string
+		propagators []any
+		want        []propagation.TextMapPropagator
+	}{
+		{
+			name:        "names",
+			propagators: []any{"b3", "b3multi", "jaeger"},
+			want: []propagation.TextMapPropagator{
+				B3Propagator{}, B3Propagator{MultipleHeaders: true}, JaegerPropagator{},
+			},
+		},
+		{
+			name:        "mixed",
+			propagators: []any{propagation.TraceContext{}, "baggage"},
+			want:        []propagation.TextMapPropagator{propagation.TraceContext{}, propagation.Baggage{}},
+		},
+		{
+			name:        "none",
+			propagators: []any{"none"},
+			want:        []propagation.TextMapPropagator{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := &TelemetryBuilder{}
+			WithPropagators(tt.propagators...)(builder)
+
+			assert.Empty(t, builder.errs)
+			assert.Equal(t, tt.want, builder.propagators)
+		})
+	}
+}
+
+// TestWithPropagatorsInvalid tests that unknown names and types are reported by Init.
+func TestWithPropagatorsInvalid(t *testing.T) {
+	tests := map[any]string{
+		"xray": `unknown propagator: "xray"`,
+		42:     "unknown propagator: int",
+	}
+
+	for propagator, want := range tests {
+		_, _, err := Init(context.Background(), "test-service", WithSilentLogger(), WithPropagators(propagator))
+
+		assert.ErrorIs(t, err, errors.ErrUnknownPropagator)
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+// TestInitWithPropagators tests that Init installs the configured propagators globally.
+func TestInitWithPropagators(t *testing.T) {
+	usePropagator(t, otel.GetTextMapPropagator())
+	initRecording(t, WithPropagators("b3multi", JaegerPropagator{}))
+
+	assert.ElementsMatch(t, []string{b3TraceID, b3SpanID, b3Sampled, jaegerHeader},
+		otel.GetTextMapPropagator().Fields())
+}
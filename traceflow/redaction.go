@@ -0,0 +1,122 @@
+package traceflow
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultRedactionReplacement replaces redacted attribute values when a rule sets no replacement.
+const defaultRedactionReplacement = "[REDACTED]"
+
+// RedactionRule describes an attribute whose value must not leave the process.
+type RedactionRule struct {
+	// Key is the attribute key to redact. A trailing "*" matches every key with that prefix,
+	// such as "http.header.*".
+	Key string
+
+	// Replacement replaces the attribute value. It defaults to "[REDACTED]".
+	Replacement string
+
+	// Remove drops the attribute entirely instead of replacing its value.
+	Remove bool
+}
+
+// matches reports whether the rule applies to the attribute key.
+func (r RedactionRule) matches(key string) bool {
+	if prefix, ok := strings.CutSuffix(r.Key, "*"); ok {
+		return strings.HasPrefix(key, prefix)
+	}
+
+	return key == r.Key
+}
+
+// WithRedaction redacts matching span and event attributes before spans are exported.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithRedaction(
+//	        traceflow.RedactionRule{Key: "http.header.Authorization"},
+//	        traceflow.RedactionRule{Key: "user.email", Remove: true},
+//	    ),
+//	)
+func WithRedaction(rules ...RedactionRule) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.redactions = append(tb.redactions, rules...)
+	}
+}
+
+// redactingExporter applies redaction rules to spans before passing them to the wrapped exporter.
+type redactingExporter struct {
+	sdktrace.SpanExporter
+	rules []RedactionRule
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *redactingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		redacted[i] = &redactedSpan{ReadOnlySpan: span, rules: e.rules}
+	}
+
+	return e.SpanExporter.ExportSpans(ctx, redacted)
+}
+
+// redactedSpan is a read-only span whose attributes have been redacted.
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	rules []RedactionRule
+}
+
+// Attributes returns the span attributes with the redaction rules applied.
+func (s *redactedSpan) Attributes() []attribute.KeyValue {
+	return redactAttributes(s.ReadOnlySpan.Attributes(), s.rules)
+}
+
+// Events returns the span events with the redaction rules applied to their attributes.
+func (s *redactedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+
+	redacted := make([]sdktrace.Event, len(events))
+	for i, event := range events {
+		event.Attributes = redactAttributes(event.Attributes, s.rules)
+		redacted[i] = event
+	}
+
+	return redacted
+}
+
+// redactAttributes returns a copy of attrs with the first matching rule applied to each attribute.
+func redactAttributes(attrs []attribute.KeyValue, rules []RedactionRule) []attribute.KeyValue {
+	redacted := make([]attribute.KeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		rule, ok := matchRedactionRule(string(attr.Key), rules)
+
+		switch {
+		case !ok:
+			redacted = append(redacted, attr)
+		case rule.Remove:
+		case rule.Replacement != "":
+			redacted = append(redacted, attr.Key.String(rule.Replacement))
+		default:
+			redacted = append(redacted, attr.Key.String(defaultRedactionReplacement))
+		}
+	}
+
+	return redacted
+}
+
+// matchRedactionRule returns the first rule matching the key.
+func matchRedactionRule(key string, rules []RedactionRule) (RedactionRule, bool) {
+	for _, rule := range rules {
+		if rule.matches(key) {
+			return rule, true
+		}
+	}
+
+	return RedactionRule{}, false
+}
@@ -0,0 +1,64 @@
+package traceflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// TestRedactAttributes tests exact and prefix rules, replacements and removal.
+func TestRedactAttributes(t *testing.T) {
+	rules := []RedactionRule{
+		{Key: "user.email", Remove: true},
+		{Key: "http.header.*", Replacement: "***"},
+		{Key: "db.statement"},
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("user.email", "jane@example.com"),
+		attribute.String("user.id", "42"),
+		attribute.String("http.header.Authorization", "Bearer token"),
+		attribute.String("db.statement", "SELECT * FROM users"),
+	}
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("user.id", "42"),
+		attribute.String("http.header.Authorization", "***"),
+		attribute.String("db.statement", defaultRedactionReplacement),
+	}, redactAttributes(attrs, rules))
+}
+
+// TestRedactingExporter tests that span and event attributes are redacted on export.
+func TestRedactingExporter(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "operation")
+	span.SetAttributes(attribute.String("password", "hunter2"))
+	span.AddEvent("login", oteltrace.WithAttributes(attribute.String("password", "hunter2")))
+	span.End()
+
+	inner := tracetest.NewInMemoryExporter()
+	exporter := &redactingExporter{SpanExporter: inner, rules: []RedactionRule{{Key: "password"}}}
+
+	require.NoError(t, exporter.ExportSpans(context.Background(), recorder.Ended()))
+
+	spans := inner.GetSpans()
+	require.Len(t, spans, 1)
+
+	password, _ := findAttribute(spans[0], "password")
+	assert.Equal(t, defaultRedactionReplacement, password.AsString())
+
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("password", defaultRedactionReplacement)},
+		spans[0].Events[0].Attributes)
+
+	// The original span is left untouched
+	assert.Equal(t, "hunter2", recorder.Ended()[0].Attributes()[0].Value.AsString())
+}
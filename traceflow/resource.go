@@ -0,0 +1,286 @@
+package traceflow
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// Names of the resource detectors run by Init, for use with WithoutDetector.
+const (
+	DetectorHost       = "host"
+	DetectorOS         = "os"
+	DetectorProcess    = "process"
+	DetectorContainer  = "container"
+	DetectorKubernetes = "kubernetes"
+	DetectorService    = "service"
+)
+
+// Environment variables read by the service and Kubernetes detectors. The Kubernetes
+// variables are expected to be set from the downward API; both the POD_* names used in the
+// Kubernetes documentation and K8S_* names are recognized.
+var (
+	envServiceVersion        = []string{"SERVICE_VERSION"}
+	envDeploymentEnvironment = []string{"DEPLOYMENT_ENVIRONMENT"}
+	envPodName               = []string{"K8S_POD_NAME", "POD_NAME"}
+	envPodUID                = []string{"K8S_POD_UID", "POD_UID"}
+	envPodNamespace          = []string{"K8S_NAMESPACE_NAME", "POD_NAMESPACE"}
+	envNodeName              = []string{"K8S_NODE_NAME", "NODE_NAME"}
+)
+
+// cgroupContainerID matches the container ID at the end of a cgroup path, such as
+// /docker/<id>, /kubepods/.../<id> or /system.slice/docker-<id>.scope.
+var cgroupContainerID = regexp.MustCompile(`(?:^|[/-])([0-9a-f]{64})(?:\.scope)?$`)
+
+// mountinfoContainerID matches the container ID in the mounts of a container runtime, which
+// is the only place it appears under cgroup v2.
+var mountinfoContainerID = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+
+// resourceDetector detects resource attributes describing where the process runs.
+type resourceDetector struct {
+	name   string
+	detect func() []attribute.KeyValue
+}
+
+// resourceDetectors are run by Init in order; later detectors win on conflicting keys.
+var resourceDetectors = []resourceDetector{
+	{name: DetectorHost, detect: detectHost},
+	{name: DetectorOS, detect: detectOS},
+	{name: DetectorProcess, detect: detectProcess},
+	{name: DetectorContainer, detect: detectContainer},
+	{name: DetectorKubernetes, detect: detectKubernetes},
+	{name: DetectorService, detect: detectService},
+}
+
+// WithResourceAttributes adds attributes to the resource that describes the service. They
+// take precedence over detected attributes and OTEL_RESOURCE_ATTRIBUTES, but not over the
+// service name passed to Init.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithResourceAttributes(attribute.String("team", "payments")),
+//	)
+func WithResourceAttributes(attrs ...attribute.KeyValue) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.resourceAttrs = append(tb.resourceAttrs, attrs...)
+	}
+}
+
+// WithoutDetector disables one of the resource detectors run by Init: DetectorHost,
+// DetectorOS, DetectorProcess, DetectorContainer, DetectorKubernetes or DetectorService.
+//
+// By default, Init records once on the resource, rather than on every span:
+//   - host: host.name and host.arch
+//   - os: os.type, and os.description read from the PRETTY_NAME of /etc/os-release where
+//     the file exists
+//   - process: process.pid, process.executable.name, process.executable.path and the
+//     process.runtime name, version and description
+//   - container: container.id, read from the cgroup of the process
+//   - kubernetes: k8s.pod.name, k8s.pod.uid, k8s.namespace.name and k8s.node.name, read from
+//     K8S_POD_NAME or POD_NAME, K8S_POD_UID or POD_UID, K8S_NAMESPACE_NAME or POD_NAMESPACE,
+//     and K8S_NODE_NAME or NODE_NAME
+//   - service: service.version, read from SERVICE_VERSION or the main module version, and
+//     deployment.environment, read from DEPLOYMENT_ENVIRONMENT
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithoutDetector(traceflow.DetectorHost),
+//	)
+func WithoutDetector(name string) InitOption {
+	return func(tb *TelemetryBuilder) {
+		for _, detector := range resourceDetectors {
+			if detector.name == name {
+				tb.disabledDetectors = append(tb.disabledDetectors, name)
+				return
+			}
+		}
+
+		tb.addError(errors.ErrUnknownDetector, fmt.Errorf("%q", name))
+	}
+}
+
+// detectResource runs the resource detectors that are not disabled.
+func (tb *TelemetryBuilder) detectResource() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	for _, detector := range resourceDetectors {
+		if !slices.Contains(tb.disabledDetectors, detector.name) {
+			attrs = append(attrs, detector.detect()...)
+		}
+	}
+
+	return attrs
+}
+
+// detectHost detects the host name and architecture.
+func detectHost() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.HostArchKey.String(runtime.GOARCH)}
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		attrs = append(attrs, semconv.HostNameKey.String(hostname))
+	}
+
+	return attrs
+}
+
+// detectOS detects the operating system.
+func detectOS() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.OSTypeKey.String(runtime.GOOS)}
+
+	// os-release is read from /etc first, with the vendor copy in /usr/lib as the fallback
+	for _, path := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		if name := osReleaseName(string(data)); name != "" {
+			attrs = append(attrs, semconv.OSDescriptionKey.String(name))
+		}
+
+		break
+	}
+
+	return attrs
+}
+
+// osReleaseName returns the PRETTY_NAME of the contents of an os-release file, such as
+// "Ubuntu 24.04.1 LTS", or an empty string if it has none.
+func osReleaseName(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(line), "PRETTY_NAME=")
+		if !ok {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+
+		return strings.Trim(value, "'")
+	}
+
+	return ""
+}
+
+// detectProcess detects the process ID, executable and Go runtime.
+func detectProcess() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.ProcessPIDKey.Int(os.Getpid()),
+		semconv.ProcessRuntimeNameKey.String("go"),
+		semconv.ProcessRuntimeVersionKey.String(runtime.Version()),
+		semconv.ProcessRuntimeDescriptionKey.String("go version " + runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH),
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		attrs = append(attrs,
+			semconv.ProcessExecutableNameKey.String(filepath.Base(executable)),
+			semconv.ProcessExecutablePathKey.String(executable),
+		)
+	}
+
+	return attrs
+}
+
+// detectContainer detects the ID of the container the process runs in, if any.
+func detectContainer() []attribute.KeyValue {
+	id := ""
+
+	if data, err := os.ReadFile("/proc/self/cgroup"); err == nil {
+		id = containerIDFromCgroup(string(data))
+	}
+
+	if data, err := os.ReadFile("/proc/self/mountinfo"); err == nil && id == "" {
+		id = containerIDFromMountinfo(string(data))
+	}
+
+	if id == "" {
+		return nil
+	}
+
+	return []attribute.KeyValue{semconv.ContainerIDKey.String(id)}
+}
+
+// containerIDFromCgroup returns the container ID found in the contents of a cgroup file.
+func containerIDFromCgroup(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		if match := cgroupContainerID.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
+
+// containerIDFromMountinfo returns the container ID found in the contents of a mountinfo
+// file.
+func containerIDFromMountinfo(data string) string {
+	if match := mountinfoContainerID.FindStringSubmatch(data); match != nil {
+		return match[1]
+	}
+
+	return ""
+}
+
+// detectKubernetes detects the pod, namespace and node from downward API variables.
+func detectKubernetes() []attribute.KeyValue {
+	return envAttributes(map[attribute.Key][]string{
+		semconv.K8SPodNameKey:       envPodName,
+		semconv.K8SPodUIDKey:        envPodUID,
+		semconv.K8SNamespaceNameKey: envPodNamespace,
+		semconv.K8SNodeNameKey:      envNodeName,
+	})
+}
+
+// detectService detects the service version and deployment environment.
+func detectService() []attribute.KeyValue {
+	attrs := envAttributes(map[attribute.Key][]string{
+		semconv.ServiceVersionKey:        envServiceVersion,
+		semconv.DeploymentEnvironmentKey: envDeploymentEnvironment,
+	})
+
+	if lookupEnv(envServiceVersion) == "" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			attrs = append(attrs, semconv.ServiceVersionKey.String(info.Main.Version))
+		}
+	}
+
+	return attrs
+}
+
+// envAttributes returns an attribute for each key whose environment variables are set.
+func envAttributes(vars map[attribute.Key][]string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		if value := lookupEnv(vars[key]); value != "" {
+			attrs = append(attrs, key.String(value))
+		}
+	}
+
+	return attrs
+}
+
+// lookupEnv returns the value of the first of the environment variables that is set.
+func lookupEnv(keys []string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,177 @@
+package traceflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// containerID is a container ID as written by container runtimes.
+const containerID = "3f4ab8c6e2a1d5b7c9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2"
+
+// TestContainerIDFromCgroup tests finding the container ID in cgroup files.
+func TestContainerIDFromCgroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgroup string
+		want   string
+	}{
+		{
+			name:   "docker",
+			cgroup: "12:devices:/docker/" + containerID + "\n11:cpu:/docker/" + containerID,
+			want:   containerID,
+		},
+		{
+			name:   "kubernetes",
+			cgroup: "4:memory:/kubepods/burstable/pod0f6a1c3e-7b2d-4a8e-9c5f-1d2e3f4a5b6c/" + containerID,
+			want:   containerID,
+		},
+		{
+			name:   "systemd scope",
+			cgroup: "1:name=systemd:/system.slice/docker-" + containerID + ".scope",
+			want:   containerID,
+		},
+		{
+			name:   "containerd",
+			cgroup: "0::/kubepods.slice/kubepods-besteffort.slice/cri-containerd-" + containerID + ".scope",
+			want:   containerID,
+		},
+		{
+			name:   "cgroup v2",
+			cgroup: "0::/",
+		},
+		{
+			name:   "not in a container",
+			cgroup: "1:name=systemd:/user.slice/user-1000.slice/session-2.scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, containerIDFromCgroup(tt.cgroup))
+		})
+	}
+
+	mountinfo := "1042 1030 0:52 /var/lib/docker/containers/" + containerID + "/hostname /etc/hostname rw,relatime - ext4 /dev/sda1 rw"
+	assert.Equal(t, containerID, containerIDFromMountinfo(mountinfo))
+	assert.Empty(t, containerIDFromMountinfo("25 1 0:23 / / rw,relatime - overlay overlay rw"))
+}
+
+// recordResource initializes a Telemetry and returns the resource of a span it exported.
+func recordResource(t *testing.T, opts ...InitOption) *resource.Resource {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	tel, err := NewTelemetry(context.Background(), "test-service",
+		append([]InitOption{WithSilentLogger(), WithSpanExporter(exporter)}, opts...)...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { tel.Shutdown(context.Background()) })
+
+	tel.Now(context.Background(), "test-service", "op").End()
+	require.NoError(t, tel.Flush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	return spans[0].Resource
+}
+
+// TestOSReleaseName tests reading the OS description from os-release files.
+func TestOSReleaseName(t *testing.T) {
+	tests := []struct {
+		name      string
+		osRelease string
+		want      string
+	}{
+		{
+			name:      "double quoted",
+			osRelease: "NAME=\"Ubuntu\"\nVERSION_ID=\"24.04\"\nPRETTY_NAME=\"Ubuntu 24.04.1 LTS\"\n",
+			want:      "Ubuntu 24.04.1 LTS",
+		},
+		{
+			name:      "single quoted",
+			osRelease: "PRETTY_NAME='Alpine Linux v3.20'",
+			want:      "Alpine Linux v3.20",
+		},
+		{
+			name:      "unquoted",
+			osRelease: "ID=debian\nPRETTY_NAME=Debian",
+			want:      "Debian",
+		},
+		{
+			name:      "missing",
+			osRelease: "NAME=Distroless\nID=distroless\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, osReleaseName(tt.osRelease))
+		})
+	}
+}
+
+// TestResourceDetection tests that detected attributes are attached to the resource, and
+// that explicit attributes and the service name take precedence.
+func TestResourceDetection(t *testing.T) {
+	t.Setenv("POD_NAME", "checkout-7d9f8")
+	t.Setenv("POD_NAMESPACE", "shop")
+	t.Setenv("K8S_NODE_NAME", "node-1")
+	t.Setenv("SERVICE_VERSION", "1.4.2")
+	t.Setenv("DEPLOYMENT_ENVIRONMENT", "staging")
+	t.Setenv(envResourceAttributes, "deployment.environment=production,team=payments")
+
+	res := recordResource(t, WithResourceAttributes(
+		attribute.String("team", "checkout"),
+		semconv.ServiceNameKey.String("overridden"),
+	))
+
+	for _, key := range []attribute.Key{semconv.HostNameKey, semconv.OSTypeKey, semconv.ProcessPIDKey, semconv.ProcessRuntimeVersionKey} {
+		_, ok := res.Set().Value(key)
+		assert.True(t, ok, "Expected %s to be detected", key)
+	}
+
+	want := map[attribute.Key]string{
+		semconv.K8SPodNameKey:            "checkout-7d9f8",
+		semconv.K8SNamespaceNameKey:      "shop",
+		semconv.K8SNodeNameKey:           "node-1",
+		semconv.ServiceVersionKey:        "1.4.2",
+		semconv.DeploymentEnvironmentKey: "production",
+		"team":                           "checkout",
+		semconv.ServiceNameKey:           "test-service",
+	}
+
+	for key, value := range want {
+		got, _ := res.Set().Value(key)
+		assert.Equal(t, value, got.AsString(), "Unexpected value for %s", key)
+	}
+}
+
+// TestWithoutDetector tests disabling resource detectors.
+func TestWithoutDetector(t *testing.T) {
+	t.Setenv("POD_NAME", "checkout-7d9f8")
+
+	res := recordResource(t, WithoutDetector(DetectorHost), WithoutDetector(DetectorKubernetes))
+
+	_, ok := res.Set().Value(semconv.HostNameKey)
+	assert.False(t, ok, "Expected the host detector to be disabled")
+
+	_, ok = res.Set().Value(semconv.K8SPodNameKey)
+	assert.False(t, ok, "Expected the kubernetes detector to be disabled")
+
+	_, ok = res.Set().Value(semconv.ProcessPIDKey)
+	assert.True(t, ok, "Expected the other detectors to run")
+
+	_, err := NewTelemetry(context.Background(), "test-service", WithSilentLogger(), WithoutDetector("cloud"))
+	assert.ErrorIs(t, err, errors.ErrUnknownDetector)
+	assert.ErrorContains(t, err, `unknown resource detector: "cloud"`)
+}
@@ -0,0 +1,50 @@
+package traceflow
+
+import "context"
+
+// Run starts a span named "<service>.<name>", calls fn with the span's context, and
+// ends the span when fn returns. If fn returns an error it is recorded with
+// RecordFailure; otherwise the span is marked successful. Panics in fn are recorded
+// on the span before being re-raised.
+//
+// Example usage:
+//
+//	err := traceflow.Run(ctx, "my-service", "load-user", func(ctx context.Context) error {
+//	    return db.LoadUser(ctx, id)
+//	})
+//
+// Notes:
+//   - Options are applied to the span before it is started, exactly as with New.
+//   - The span is always ended, even on early returns or panics.
+func Run(ctx context.Context, service, name string, fn func(context.Context) error, opts ...Option) error {
+	_, err := Do(ctx, service, name, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, opts...)
+
+	return err
+}
+
+// Do is the generic counterpart of Run for functions that return a value. It starts a
+// span, calls fn with the span's context, records the returned error or marks the span
+// successful, and always ends the span. The value and error returned by fn are passed
+// through unchanged.
+//
+// Example usage:
+//
+//	user, err := traceflow.Do(ctx, "my-service", "load-user", func(ctx context.Context) (*User, error) {
+//	    return db.LoadUser(ctx, id)
+//	})
+func Do[T any](ctx context.Context, service, name string, fn func(context.Context) (T, error), opts ...Option) (T, error) {
+	trace := Now(ctx, service, name, opts...)
+	defer trace.EndWithRecover()
+
+	result, err := fn(trace.GetContext())
+	if err != nil {
+		trace.RecordFailure(err, err.Error())
+		return result, err
+	}
+
+	trace.SetSuccess(name + " completed")
+
+	return result, nil
+}
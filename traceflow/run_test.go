@@ -0,0 +1,109 @@
+package traceflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// useRecordingProvider installs a global tracer provider backed by an in-memory exporter
+// for the duration of the test.
+func useRecordingProvider(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+
+	otel.SetTracerProvider(tp)
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+
+		_ = tp.Shutdown(context.Background())
+	})
+
+	return exporter
+}
+
+// TestRunSuccess tests that Run passes the span context to fn and marks the span successful.
+func TestRunSuccess(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	var spanCtx oteltrace.SpanContext
+
+	err := Run(context.Background(), "test-service", "work", func(ctx context.Context) error {
+		spanCtx = oteltrace.SpanContextFromContext(ctx)
+		return nil
+	}, WithAttributes(AddString("job", "sync")))
+
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "test-service.work", spans[0].Name)
+	assert.Equal(t, spanCtx.SpanID(), spans[0].SpanContext.SpanID(), "Expected fn to receive the span's context")
+	assert.Equal(t, codes.Ok, spans[0].Status.Code)
+
+	if v, ok := findAttribute(spans[0], "job"); !ok || v.AsString() != "sync" {
+		t.Errorf("Expected 'job' attribute from options, got %v", v)
+	}
+}
+
+// TestRunFailure tests that Run records the returned error and ends the span.
+func TestRunFailure(t *testing.T) {
+	exporter := useRecordingProvider(t)
+	expected := errors.New("upstream unavailable")
+
+	err := Run(context.Background(), "test-service", "work", func(context.Context) error {
+		return expected
+	})
+
+	assert.ErrorIs(t, err, expected)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "upstream unavailable", spans[0].Status.Description)
+	assert.NotEmpty(t, spans[0].Events, "Expected the error to be recorded as an event")
+}
+
+// TestDo tests that Do returns the value produced by fn and nests spans created inside it.
+func TestDo(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	value, err := Do(context.Background(), "test-service", "compute", func(ctx context.Context) (int, error) {
+		defer New(ctx, "test-service").Start("inner").End()
+		return 42, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "test-service.inner", spans[0].Name)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+}
+
+// TestDoPanic tests that Do ends the span when fn panics.
+func TestDoPanic(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	assert.Panics(t, func() {
+		_, _ = Do(context.Background(), "test-service", "explode", func(context.Context) (string, error) {
+			panic("boom")
+		})
+	})
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
@@ -0,0 +1,140 @@
+package traceflow
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithSampler sets the sampler used to decide which spans are recorded. By default every
+// span is sampled.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service", traceflow.WithSampler(sdktrace.NeverSample()))
+func WithSampler(sampler sdktrace.Sampler) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.sampler = sampler
+	}
+}
+
+// WithTraceIDRatio samples the given fraction of traces, based on the trace ID. A ratio of
+// 1 samples every trace and a ratio of 0 samples none.
+//
+// Example usage:
+//
+//	// Keep 10% of traces
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service", traceflow.WithTraceIDRatio(0.1))
+func WithTraceIDRatio(ratio float64) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.sampler = sdktrace.TraceIDRatioBased(ratio)
+	}
+}
+
+// WithRateLimitedSampler samples at most perSecond new traces per second, smoothing bursts
+// with a token bucket that holds up to one second's worth of traces. The limit only applies
+// to root spans; child spans follow the decision of their parent, so sampled traces are kept
+// whole.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service", traceflow.WithRateLimitedSampler(100))
+func WithRateLimitedSampler(perSecond float64) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.sampler = sdktrace.ParentBased(newRateLimitedSampler(perSecond))
+	}
+}
+
+// WithParentBased makes the configured sampler respect the sampling decision of the parent
+// span. The configured sampler (or AlwaysSample if none is set) is only consulted for root
+// spans. Options from the SDK, such as sdktrace.WithRemoteParentNotSampled, customize how
+// each kind of parent is handled.
+//
+// Example usage:
+//
+//	// Sample 10% of new traces, but always follow the decision of upstream services
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithTraceIDRatio(0.1),
+//	    traceflow.WithParentBased(),
+//	)
+func WithParentBased(opts ...sdktrace.ParentBasedSamplerOption) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.useParent = true
+		tb.parentBased = opts
+	}
+}
+
+// buildSampler returns the sampler configured through the InitOptions.
+func (tb *TelemetryBuilder) buildSampler() sdktrace.Sampler {
+	sampler := tb.sampler
+	if sampler == nil {
+		sampler = sdktrace.AlwaysSample()
+	}
+
+	if tb.useParent {
+		return sdktrace.ParentBased(sampler, tb.parentBased...)
+	}
+
+	return sampler
+}
+
+// rateLimitedSampler is a token bucket sampler that records at most a fixed number of
+// spans per second. WithRateLimitedSampler only consults it for root spans.
+type rateLimitedSampler struct {
+	mu        sync.Mutex
+	perSecond float64
+	tokens    float64
+	last      time.Time
+	now       func() time.Time
+}
+
+// newRateLimitedSampler creates a rateLimitedSampler that starts with a full bucket.
+func newRateLimitedSampler(perSecond float64) *rateLimitedSampler {
+	perSecond = math.Max(perSecond, 0)
+
+	return &rateLimitedSampler{
+		perSecond: perSecond,
+		tokens:    math.Max(perSecond, 1),
+		last:      time.Now(),
+		now:       time.Now,
+	}
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s *rateLimitedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := sdktrace.SamplingResult{
+		Decision:   sdktrace.Drop,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+
+	if s.perSecond == 0 {
+		return result
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Refill the bucket for the time elapsed since the last decision
+	now := s.now()
+	s.tokens = math.Min(s.tokens+now.Sub(s.last).Seconds()*s.perSecond, math.Max(s.perSecond, 1))
+	s.last = now
+
+	if s.tokens >= 1 {
+		s.tokens--
+		result.Decision = sdktrace.RecordAndSample
+	}
+
+	return result
+}
+
+// Description implements sdktrace.Sampler.
+func (s *rateLimitedSampler) Description() string {
+	return fmt.Sprintf("RateLimitedSampler{%g}", s.perSecond)
+}
+
+// Ensure rateLimitedSampler implements sdktrace.Sampler
+var _ sdktrace.Sampler = (*rateLimitedSampler)(nil)
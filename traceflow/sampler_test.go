@@ -0,0 +1,169 @@
+package traceflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// initRecording runs Init with an in-memory exporter and returns a function that flushes
+// the batch processor and returns the exported spans.
+func initRecording(t *testing.T, opts ...InitOption) func() tracetest.SpanStubs {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		append([]InitOption{WithSilentLogger(), WithSpanExporter(exporter)}, opts...)...)
+	if err != nil {
+		t.Fatalf("Failed to initialize OpenTelemetry: %v", err)
+	}
+
+	tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	if !ok {
+		t.Fatal("Expected Init to install an SDK tracer provider")
+	}
+
+	t.Cleanup(func() {
+		shutdown(ctx)
+		otel.SetTracerProvider(previous)
+	})
+
+	return func() tracetest.SpanStubs {
+		if err := tp.ForceFlush(ctx); err != nil {
+			t.Fatalf("Failed to flush spans: %v", err)
+		}
+
+		return exporter.GetSpans()
+	}
+}
+
+// startSpans starts and ends n root spans.
+func startSpans(n int) {
+	for i := 0; i < n; i++ {
+		New(context.Background(), "test-service").Start("sampled").End()
+	}
+}
+
+// TestInitDefaultSampler tests that every span is sampled by default.
+func TestInitDefaultSampler(t *testing.T) {
+	spans := initRecording(t)
+
+	startSpans(100)
+
+	assert.Len(t, spans(), 100)
+}
+
+// TestWithTraceIDRatio tests that roughly the configured fraction of traces is sampled.
+func TestWithTraceIDRatio(t *testing.T) {
+	spans := initRecording(t, WithTraceIDRatio(0.1))
+
+	const total = 5000
+
+	startSpans(total)
+
+	fraction := float64(len(spans())) / total
+	assert.InDelta(t, 0.1, fraction, 0.03, "Expected about 10%% of spans to be sampled, got %.3f", fraction)
+}
+
+// TestWithSampler tests that a custom sampler is used.
+func TestWithSampler(t *testing.T) {
+	spans := initRecording(t, WithSampler(sdktrace.NeverSample()))
+
+	startSpans(50)
+
+	assert.Empty(t, spans())
+}
+
+// TestWithParentBased tests that parent-based sampling follows the upstream decision and
+// only applies the root sampler to new traces.
+func TestWithParentBased(t *testing.T) {
+	spans := initRecording(t, WithTraceIDRatio(0), WithParentBased())
+
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9},
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+
+	New(trace.ContextWithRemoteSpanContext(context.Background(), remote), "test-service").Start("child").End()
+	startSpans(50)
+
+	exported := spans()
+	if assert.Len(t, exported, 1, "Expected only the span with a sampled parent to be exported") {
+		assert.Equal(t, remote.TraceID(), exported[0].SpanContext.TraceID())
+	}
+}
+
+// TestWithRateLimitedSampler tests that bursts are capped at the configured rate.
+func TestWithRateLimitedSampler(t *testing.T) {
+	spans := initRecording(t, WithRateLimitedSampler(10))
+
+	startSpans(100)
+
+	assert.InDelta(t, 10, len(spans()), 1, "Expected the burst to be limited to about 10 spans")
+}
+
+// TestWithRateLimitedSamplerKeepsTraces tests that the children of a sampled root are kept
+// after the budget is spent, and the children of a dropped root are dropped.
+func TestWithRateLimitedSamplerKeepsTraces(t *testing.T) {
+	spans := initRecording(t, WithRateLimitedSampler(1))
+
+	for range 2 {
+		root := New(context.Background(), "test-service").Start("root")
+
+		for range 20 {
+			root.StartChild("child").End()
+		}
+
+		root.End()
+	}
+
+	recorded := spans()
+	require.Len(t, recorded, 21, "Expected the first trace to be kept whole and the second dropped")
+
+	traceID := recorded[0].SpanContext.TraceID()
+	for _, span := range recorded {
+		assert.Equal(t, traceID, span.SpanContext.TraceID())
+	}
+}
+
+// TestRateLimitedSamplerRefill tests that tokens are refilled over time.
+func TestRateLimitedSamplerRefill(t *testing.T) {
+	now := time.Now()
+	sampler := newRateLimitedSampler(2)
+	sampler.now = func() time.Time { return now }
+	sampler.last = now
+
+	decisions := func(n int) int {
+		sampled := 0
+
+		for i := 0; i < n; i++ {
+			if sampler.ShouldSample(sdktrace.SamplingParameters{}).Decision == sdktrace.RecordAndSample {
+				sampled++
+			}
+		}
+
+		return sampled
+	}
+
+	assert.Equal(t, 2, decisions(5), "Expected the initial bucket to hold one second of spans")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, 1, decisions(5), "Expected half a second to refill one token")
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, 2, decisions(5), "Expected the bucket to be capped at one second of spans")
+
+	assert.Equal(t, sdktrace.Drop, newRateLimitedSampler(0).ShouldSample(sdktrace.SamplingParameters{}).Decision)
+	assert.Equal(t, "RateLimitedSampler{2}", sampler.Description())
+}
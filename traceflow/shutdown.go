@@ -0,0 +1,260 @@
+package traceflow
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultShutdownTimeout bounds the shutdown triggered by WithShutdownOnSignal.
+const defaultShutdownTimeout = 5 * time.Second
+
+// raise sends sig to the process again once the handler has been removed, so that the
+// process exits as it would have without WithShutdownOnSignal.
+var raise = func(sig os.Signal) {
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(sig)
+	}
+
+	// Signals that cannot be re-sent, such as os.Interrupt on Windows, still end the process
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// flusher is implemented by providers that can export their buffered data on demand.
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// WithShutdownOnSignal flushes and shuts down the tracer and meter providers when one of the
+// signals is received, so that short-lived jobs and pods that are stopped do not lose their
+// last spans. The shutdown is bounded by WithShutdownTimeout, and the number of spans that
+// were still queued when it ended, or were dropped because a batch queue was full, is logged.
+// The signal is then raised again, so the process exits as it would have without the option.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-job",
+//	    traceflow.WithOLTP("otel:4317"),
+//	    traceflow.WithShutdownOnSignal(os.Interrupt, syscall.SIGTERM),
+//	)
+//
+// Notes:
+//   - Calling the shutdown function returned by Init stops watching for the signals.
+//   - Because the signal is raised again, an application that also registered the signals
+//     with signal.Notify receives each of them twice: once when it is first delivered and
+//     once after the shutdown. Applications that handle the signals themselves should call
+//     the shutdown function returned by Init from their handler instead of using this option.
+func WithShutdownOnSignal(signals ...os.Signal) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.shutdownSignals = append(tb.shutdownSignals, signals...)
+
+		// The spans are only counted when the count is reported
+		if tb.queue == nil {
+			tb.queue = &spanQueue{}
+		}
+	}
+}
+
+// WithShutdownTimeout sets the deadline of the shutdown triggered by WithShutdownOnSignal.
+// It defaults to 5 seconds, well within the 30 second grace period Kubernetes gives a pod.
+func WithShutdownTimeout(timeout time.Duration) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.shutdownTimeout = timeout
+	}
+}
+
+// Flush exports the spans and metrics buffered by the global providers without shutting
+// them down. See Telemetry.Flush.
+//
+// Example usage:
+//
+//	if err := traceflow.Flush(ctx); err != nil {
+//	    log.Printf("Failed to flush telemetry: %v", err)
+//	}
+func Flush(ctx context.Context) error {
+	return defaultTelemetry.Flush(ctx)
+}
+
+// Flush exports the spans and metrics buffered by the Telemetry's providers, waiting until
+// they are exported or ctx is done. Unlike Shutdown, the providers remain usable, so Flush
+// can be called mid-run, for example at the end of each job of a worker.
+func (tel *Telemetry) Flush(ctx context.Context) error {
+	var errs []error
+
+	if tp, ok := tel.TracerProvider().(flusher); ok {
+		ended := tel.ended()
+
+		if err := tp.ForceFlush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", errors.ErrTracerProviderFlush, err))
+		} else {
+			tel.settle(ended)
+		}
+	}
+
+	if mp, ok := tel.MeterProvider().(flusher); ok {
+		if err := mp.ForceFlush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w", errors.ErrMeterProviderFlush, err))
+		}
+	}
+
+	return stderrors.Join(errs...)
+}
+
+// Queued returns the number of sampled spans that have ended but have not yet been handed
+// to an exporter. Until a Flush or Shutdown completes, it includes the spans the batch
+// processors dropped because their queue was full. It is always zero unless the Telemetry
+// was created with WithShutdownOnSignal.
+func (tel *Telemetry) Queued() int64 {
+	if tel == nil || tel.queue == nil {
+		return 0
+	}
+
+	return tel.queue.queued()
+}
+
+// Dropped returns the number of sampled spans that the batch processors dropped because
+// their queue was full. Drops are found when a Flush or Shutdown completes: the spans that
+// ended before it and were not exported by it are counted as dropped, and no longer as
+// queued. It is always zero unless the Telemetry was created with WithShutdownOnSignal.
+func (tel *Telemetry) Dropped() int64 {
+	if tel == nil || tel.queue == nil {
+		return 0
+	}
+
+	return tel.queue.dropped.Load()
+}
+
+// ended returns the number of sampled spans ended so far.
+func (tel *Telemetry) ended() int64 {
+	if tel == nil || tel.queue == nil {
+		return 0
+	}
+
+	return tel.queue.ended.Load()
+}
+
+// settle counts the spans ended before a completed flush or shutdown that were not exported
+// as dropped.
+func (tel *Telemetry) settle(ended int64) {
+	if tel != nil && tel.queue != nil {
+		tel.queue.settle(ended)
+	}
+}
+
+// watchSignals shuts down the Telemetry when one of the signals is received, then raises the
+// signal again. The returned function stops watching.
+func (tel *Telemetry) watchSignals(signals []os.Signal, timeout time.Duration, logger *log.Logger) func() {
+	received := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(received, signals...)
+
+	go func() {
+		select {
+		case sig := <-received:
+			tel.shutdownOnSignal(sig, timeout, logger)
+			raise(sig)
+		case <-done:
+		}
+	}()
+
+	return sync.OnceFunc(func() {
+		signal.Stop(received)
+		close(done)
+	})
+}
+
+// shutdownOnSignal shuts down the Telemetry within timeout and logs the outcome.
+func (tel *Telemetry) shutdownOnSignal(sig os.Signal, timeout time.Duration, logger *log.Logger) {
+	logger.Printf("Received %v, shutting down OpenTelemetry", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ended := tel.ended()
+
+	if err := tel.Shutdown(ctx); err != nil {
+		logger.Printf("Failed to shut down OpenTelemetry: %v", err)
+	} else {
+		tel.settle(ended)
+	}
+
+	if queued := tel.Queued(); queued > 0 {
+		logger.Printf("OpenTelemetry shut down with %d spans still queued", queued)
+	}
+
+	if dropped := tel.Dropped(); dropped > 0 {
+		logger.Printf("OpenTelemetry dropped %d spans because the batch queue was full", dropped)
+	}
+}
+
+// spanQueue counts the sampled spans passed to the batch processors, the spans handed to
+// their exporters and the spans found to be dropped. It only observes the processors, which
+// decide on their own which spans to drop.
+type spanQueue struct {
+	ended    atomic.Int64
+	exported atomic.Int64
+	dropped  atomic.Int64
+
+	settleMu sync.Mutex
+}
+
+// queued returns the number of spans that have ended but have been neither exported nor
+// found to be dropped.
+func (q *spanQueue) queued() int64 {
+	return max(q.ended.Load()-q.exported.Load()-q.dropped.Load(), 0)
+}
+
+// settle counts as dropped the spans that ended before a flush or shutdown that completed,
+// but were not exported by it. ended is the number of spans ended when the flush started.
+// Spans ended since then and already exported make the count err on the side of queued.
+func (q *spanQueue) settle(ended int64) {
+	q.settleMu.Lock()
+	defer q.settleMu.Unlock()
+
+	if lost := ended - q.exported.Load() - q.dropped.Load(); lost > 0 {
+		q.dropped.Add(lost)
+	}
+}
+
+// queueingProcessor counts the sampled spans passed to the wrapped batch processor.
+type queueingProcessor struct {
+	sdktrace.SpanProcessor
+	queue *spanQueue
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *queueingProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	// The batch processor ignores spans that are not sampled
+	if span.SpanContext().IsSampled() {
+		p.queue.ended.Add(1)
+	}
+
+	p.SpanProcessor.OnEnd(span)
+}
+
+// queueingExporter counts the spans handed to the wrapped exporter, whether or not the
+// export succeeds.
+type queueingExporter struct {
+	sdktrace.SpanExporter
+	queue *spanQueue
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *queueingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	defer e.queue.exported.Add(int64(len(spans)))
+
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
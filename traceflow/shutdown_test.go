@@ -0,0 +1,241 @@
+package traceflow
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stubRaise records the signals raised again after a signal-driven shutdown, instead of
+// sending them to the test process.
+func stubRaise(t *testing.T) <-chan os.Signal {
+	t.Helper()
+
+	raised := make(chan os.Signal, 1)
+	previous := raise
+
+	raise = func(sig os.Signal) { raised <- sig }
+	t.Cleanup(func() { raise = previous })
+
+	return raised
+}
+
+// TestTelemetryFlush tests that Flush exports ended spans while the provider stays usable.
+func TestTelemetryFlush(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	tel, err := NewTelemetry(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithSpanExporter(exporter),
+		WithBatchTimeout(time.Hour),
+		WithShutdownOnSignal(os.Interrupt),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { tel.Shutdown(context.Background()) })
+
+	tel.Now(context.Background(), "test-service", "first").End()
+	assert.Empty(t, exporter.GetSpans(), "Expected the span to wait for the batch timeout")
+	assert.Equal(t, int64(1), tel.Queued())
+
+	require.NoError(t, tel.Flush(context.Background()))
+	assert.Len(t, exporter.GetSpans(), 1)
+	assert.Zero(t, tel.Queued())
+
+	tel.Now(context.Background(), "test-service", "second").End()
+	require.NoError(t, tel.Flush(context.Background()))
+	assert.Len(t, exporter.GetSpans(), 2)
+}
+
+// gatedExporter holds every export until it is released, recording the exported spans.
+type gatedExporter struct {
+	tracetest.InMemoryExporter
+	started chan struct{}
+	release chan struct{}
+}
+
+func newGatedExporter() *gatedExporter {
+	return &gatedExporter{started: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (e *gatedExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	select {
+	case e.started <- struct{}{}:
+	default:
+	}
+
+	<-e.release
+
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+// backPressureOptions are batch options under which the processor blocks on its first export
+// of two spans and queues four more.
+var backPressureOptions = []sdktrace.BatchSpanProcessorOption{
+	sdktrace.WithBatchTimeout(time.Hour),
+	sdktrace.WithMaxQueueSize(4),
+	sdktrace.WithMaxExportBatchSize(2),
+}
+
+// applyBackPressure ends two spans that block the exporter, then extra more spans while it is
+// blocked, and releases it.
+func applyBackPressure(t *testing.T, tp trace.TracerProvider, exporter *gatedExporter, extra int) {
+	t.Helper()
+
+	tracer := tp.Tracer("test-service")
+
+	for range 2 {
+		_, span := tracer.Start(context.Background(), "job")
+		span.End()
+	}
+
+	select {
+	case <-exporter.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the first batch to be exported")
+	}
+
+	for range extra {
+		_, span := tracer.Start(context.Background(), "job")
+		span.End()
+	}
+
+	close(exporter.release)
+}
+
+// TestTelemetryBackPressure tests that counting the queued spans does not change which spans
+// the batch processor accepts.
+func TestTelemetryBackPressure(t *testing.T) {
+	sdkExporter := newGatedExporter()
+	sdkProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(sdkExporter, backPressureOptions...),
+	)
+
+	t.Cleanup(func() { sdkProvider.Shutdown(context.Background()) })
+
+	applyBackPressure(t, sdkProvider, sdkExporter, 4)
+	require.NoError(t, sdkProvider.ForceFlush(context.Background()))
+
+	exporter := newGatedExporter()
+
+	tel, err := NewTelemetry(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithSpanExporter(exporter),
+		WithBatchOptions(backPressureOptions...),
+		WithShutdownOnSignal(os.Interrupt),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { tel.Shutdown(context.Background()) })
+
+	applyBackPressure(t, tel.TracerProvider(), exporter, 4)
+	require.NoError(t, tel.Flush(context.Background()))
+
+	assert.Len(t, sdkExporter.GetSpans(), 6)
+	assert.Len(t, exporter.GetSpans(), len(sdkExporter.GetSpans()))
+	assert.Zero(t, tel.Dropped())
+	assert.Zero(t, tel.Queued())
+}
+
+// TestTelemetryDropped tests that spans dropped by a full batch queue are counted apart from
+// the queued ones once the processor has been flushed.
+func TestTelemetryDropped(t *testing.T) {
+	exporter := newGatedExporter()
+
+	var logOutput bytes.Buffer
+
+	logger := log.New(&logOutput, "", 0)
+
+	tel, err := NewTelemetry(context.Background(), "test-service",
+		WithLogger(logger),
+		WithSpanExporter(exporter),
+		WithBatchOptions(backPressureOptions...),
+		WithShutdownOnSignal(os.Interrupt),
+	)
+	require.NoError(t, err)
+
+	// The queue holds four spans while the first two are exported, so the last two are dropped
+	applyBackPressure(t, tel.TracerProvider(), exporter, 6)
+
+	require.NoError(t, tel.Flush(context.Background()))
+	assert.Len(t, exporter.GetSpans(), 6)
+	assert.Zero(t, tel.Queued(), "Expected dropped spans not to be reported as queued")
+	assert.Equal(t, int64(2), tel.Dropped())
+
+	tel.shutdownOnSignal(os.Interrupt, time.Second, logger)
+	assert.NotContains(t, logOutput.String(), "still queued")
+	assert.Contains(t, logOutput.String(), "OpenTelemetry dropped 2 spans because the batch queue was full")
+}
+
+// TestShutdownOnSignal tests that a signal flushes the spans, shuts the providers down and
+// is raised again.
+func TestShutdownOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Sending os.Interrupt to a process is not supported on Windows")
+	}
+
+	raised := stubRaise(t)
+	exporter := &unreachableExporter{}
+
+	var logOutput bytes.Buffer
+
+	tel, err := NewTelemetry(context.Background(), "test-service",
+		WithSpanExporter(exporter),
+		WithBatchTimeout(time.Hour),
+		WithLogger(log.New(&logOutput, "", 0)),
+		WithShutdownOnSignal(os.Interrupt),
+	)
+	require.NoError(t, err)
+
+	tel.Now(context.Background(), "test-service", "job").End()
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(os.Interrupt))
+
+	select {
+	case sig := <-raised:
+		assert.Equal(t, os.Interrupt, sig)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the signal to be raised again after shutdown")
+	}
+
+	assert.Equal(t, []string{"test-service.job"}, exporter.exported())
+	assert.Contains(t, logOutput.String(), "Received interrupt, shutting down OpenTelemetry")
+	assert.NotContains(t, logOutput.String(), "still queued")
+	assert.NoError(t, tel.Shutdown(context.Background()), "Expected a second shutdown to be a no-op")
+}
+
+// TestShutdownOnSignalReportsQueuedSpans tests that spans the deadline did not leave time to
+// export are reported.
+func TestShutdownOnSignalReportsQueuedSpans(t *testing.T) {
+	exporter := &blockingExporter{release: make(chan struct{})}
+	t.Cleanup(func() { close(exporter.release) })
+
+	var logOutput bytes.Buffer
+
+	logger := log.New(&logOutput, "", 0)
+
+	tel, err := NewTelemetry(context.Background(), "test-service",
+		WithSpanExporter(exporter),
+		WithLogger(logger),
+		WithShutdownOnSignal(os.Interrupt),
+	)
+	require.NoError(t, err)
+
+	tel.Now(context.Background(), "test-service", "job").End()
+	tel.shutdownOnSignal(os.Interrupt, 20*time.Millisecond, logger)
+
+	assert.Contains(t, logOutput.String(), "Failed to shut down OpenTelemetry")
+	assert.Contains(t, logOutput.String(), "OpenTelemetry shut down with 1 spans still queued")
+}
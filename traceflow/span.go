@@ -1,6 +1,11 @@
 package traceflow
 
-import "go.opentelemetry.io/otel/trace"
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
 
 // SpanContext wraps the OpenTelemetry span context type.
 type SpanContext struct {
@@ -13,6 +18,23 @@ type SpanKind struct {
 	option trace.SpanStartOption
 }
 
+// spanEvent holds an event recorded before its span was started.
+type spanEvent struct {
+	name      string
+	timestamp time.Time
+	attrs     []attribute.KeyValue
+}
+
+// options converts the event into OpenTelemetry event options.
+func (e spanEvent) options() []trace.EventOption {
+	opts := []trace.EventOption{trace.WithTimestamp(e.timestamp)}
+	if len(e.attrs) > 0 {
+		opts = append(opts, trace.WithAttributes(e.attrs...))
+	}
+
+	return opts
+}
+
 // NewSpanContext creates a new SpanContext from OpenTelemetry's span context.
 func NewSpanContext(sc trace.SpanContext) SpanContext {
 	return SpanContext{otelSpanContext: sc}
@@ -20,44 +42,40 @@ func NewSpanContext(sc trace.SpanContext) SpanContext {
 
 // Server sets the span kind to server and returns the Trace object for chaining.
 func (t *Trace) Server() *Trace {
-	if t.spanKind == nil {
-		t.spanKind = &SpanKind{trace: t}
-	}
-
-	t.spanKind.option = trace.WithSpanKind(trace.SpanKindServer)
+	t.setSpanKind(trace.SpanKindServer)
 
 	return t
 }
 
 // Client sets the span kind to client and returns the Trace object for chaining.
 func (t *Trace) Client() *Trace {
-	if t.spanKind == nil {
-		t.spanKind = &SpanKind{trace: t}
-	}
-
-	t.spanKind.option = trace.WithSpanKind(trace.SpanKindClient)
+	t.setSpanKind(trace.SpanKindClient)
 
 	return t
 }
 
 // Producer sets the span kind to producer and returns the Trace object for chaining.
 func (t *Trace) Producer() *Trace {
-	if t.spanKind == nil {
-		t.spanKind = &SpanKind{trace: t}
-	}
-
-	t.spanKind.option = trace.WithSpanKind(trace.SpanKindProducer)
+	t.setSpanKind(trace.SpanKindProducer)
 
 	return t
 }
 
 // Consumer sets the span kind to consumer and returns the Trace object for chaining.
 func (t *Trace) Consumer() *Trace {
+	t.setSpanKind(trace.SpanKindConsumer)
+
+	return t
+}
+
+// setSpanKind records the span kind to apply when the span is started.
+func (t *Trace) setSpanKind(kind trace.SpanKind) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	if t.spanKind == nil {
 		t.spanKind = &SpanKind{trace: t}
 	}
 
-	t.spanKind.option = trace.WithSpanKind(trace.SpanKindConsumer)
-
-	return t
+	t.spanKind.option = trace.WithSpanKind(kind)
 }
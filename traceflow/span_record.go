@@ -0,0 +1,350 @@
+package traceflow
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanRecord is the serialized form of a finished span. Unlike tracetest.SpanStub it can be
+// decoded again, so spans written to disk can be exported later.
+type spanRecord struct {
+	Name              string            `json:"name"`
+	SpanContext       spanContextRecord `json:"span_context"`
+	Parent            spanContextRecord `json:"parent"`
+	Kind              trace.SpanKind    `json:"kind"`
+	StartTime         time.Time         `json:"start_time"`
+	EndTime           time.Time         `json:"end_time"`
+	Attributes        []attributeRecord `json:"attributes,omitempty"`
+	Events            []eventRecord     `json:"events,omitempty"`
+	Links             []linkRecord      `json:"links,omitempty"`
+	StatusCode        codes.Code        `json:"status_code"`
+	StatusDescription string            `json:"status_description,omitempty"`
+	DroppedAttributes int               `json:"dropped_attributes,omitempty"`
+	DroppedEvents     int               `json:"dropped_events,omitempty"`
+	DroppedLinks      int               `json:"dropped_links,omitempty"`
+	ChildSpanCount    int               `json:"child_span_count,omitempty"`
+	Resource          []attributeRecord `json:"resource,omitempty"`
+	ResourceSchemaURL string            `json:"resource_schema_url,omitempty"`
+	Scope             scopeRecord       `json:"scope"`
+}
+
+// spanContextRecord is the serialized form of a trace.SpanContext.
+type spanContextRecord struct {
+	TraceID    string `json:"trace_id"`
+	SpanID     string `json:"span_id"`
+	TraceFlags byte   `json:"trace_flags,omitempty"`
+	TraceState string `json:"trace_state,omitempty"`
+	Remote     bool   `json:"remote,omitempty"`
+}
+
+// attributeRecord is the serialized form of an attribute, keeping its type so that integers
+// and slices decode to the same attribute type.
+type attributeRecord struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// eventRecord is the serialized form of a span event.
+type eventRecord struct {
+	Name              string            `json:"name"`
+	Time              time.Time         `json:"time"`
+	Attributes        []attributeRecord `json:"attributes,omitempty"`
+	DroppedAttributes int               `json:"dropped_attributes,omitempty"`
+}
+
+// linkRecord is the serialized form of a span link.
+type linkRecord struct {
+	SpanContext       spanContextRecord `json:"span_context"`
+	Attributes        []attributeRecord `json:"attributes,omitempty"`
+	DroppedAttributes int               `json:"dropped_attributes,omitempty"`
+}
+
+// scopeRecord is the serialized form of the instrumentation scope.
+type scopeRecord struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	SchemaURL string `json:"schema_url,omitempty"`
+}
+
+// newSpanRecord converts a finished span into its serialized form.
+func newSpanRecord(span sdktrace.ReadOnlySpan) (spanRecord, error) {
+	var err error
+
+	record := spanRecord{
+		Name:              span.Name(),
+		SpanContext:       newSpanContextRecord(span.SpanContext()),
+		Parent:            newSpanContextRecord(span.Parent()),
+		Kind:              span.SpanKind(),
+		StartTime:         span.StartTime(),
+		EndTime:           span.EndTime(),
+		StatusCode:        span.Status().Code,
+		StatusDescription: span.Status().Description,
+		DroppedAttributes: span.DroppedAttributes(),
+		DroppedEvents:     span.DroppedEvents(),
+		DroppedLinks:      span.DroppedLinks(),
+		ChildSpanCount:    span.ChildSpanCount(),
+		Scope: scopeRecord{
+			Name:      span.InstrumentationScope().Name,
+			Version:   span.InstrumentationScope().Version,
+			SchemaURL: span.InstrumentationScope().SchemaURL,
+		},
+	}
+
+	if record.Attributes, err = newAttributeRecords(span.Attributes()); err != nil {
+		return spanRecord{}, err
+	}
+
+	for _, event := range span.Events() {
+		attrs, err := newAttributeRecords(event.Attributes)
+		if err != nil {
+			return spanRecord{}, err
+		}
+
+		record.Events = append(record.Events, eventRecord{
+			Name:              event.Name,
+			Time:              event.Time,
+			Attributes:        attrs,
+			DroppedAttributes: event.DroppedAttributeCount,
+		})
+	}
+
+	for _, link := range span.Links() {
+		attrs, err := newAttributeRecords(link.Attributes)
+		if err != nil {
+			return spanRecord{}, err
+		}
+
+		record.Links = append(record.Links, linkRecord{
+			SpanContext:       newSpanContextRecord(link.SpanContext),
+			Attributes:        attrs,
+			DroppedAttributes: link.DroppedAttributeCount,
+		})
+	}
+
+	if res := span.Resource(); res != nil {
+		record.ResourceSchemaURL = res.SchemaURL()
+
+		if record.Resource, err = newAttributeRecords(res.Attributes()); err != nil {
+			return spanRecord{}, err
+		}
+	}
+
+	return record, nil
+}
+
+// snapshot rebuilds the finished span from its serialized form.
+func (r spanRecord) snapshot() (sdktrace.ReadOnlySpan, error) {
+	spanContext, err := r.SpanContext.spanContext()
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := r.Parent.spanContext()
+	if err != nil {
+		return nil, err
+	}
+
+	stub := tracetest.SpanStub{
+		Name:              r.Name,
+		SpanContext:       spanContext,
+		Parent:            parent,
+		SpanKind:          r.Kind,
+		StartTime:         r.StartTime,
+		EndTime:           r.EndTime,
+		Status:            sdktrace.Status{Code: r.StatusCode, Description: r.StatusDescription},
+		DroppedAttributes: r.DroppedAttributes,
+		DroppedEvents:     r.DroppedEvents,
+		DroppedLinks:      r.DroppedLinks,
+		ChildSpanCount:    r.ChildSpanCount,
+		InstrumentationScope: instrumentation.Scope{
+			Name:      r.Scope.Name,
+			Version:   r.Scope.Version,
+			SchemaURL: r.Scope.SchemaURL,
+		},
+	}
+
+	if stub.Attributes, err = decodeAttributes(r.Attributes); err != nil {
+		return nil, err
+	}
+
+	for _, event := range r.Events {
+		attrs, err := decodeAttributes(event.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		stub.Events = append(stub.Events, sdktrace.Event{
+			Name:                  event.Name,
+			Time:                  event.Time,
+			Attributes:            attrs,
+			DroppedAttributeCount: event.DroppedAttributes,
+		})
+	}
+
+	for _, link := range r.Links {
+		linkContext, err := link.SpanContext.spanContext()
+		if err != nil {
+			return nil, err
+		}
+
+		attrs, err := decodeAttributes(link.Attributes)
+		if err != nil {
+			return nil, err
+		}
+
+		stub.Links = append(stub.Links, sdktrace.Link{
+			SpanContext:           linkContext,
+			Attributes:            attrs,
+			DroppedAttributeCount: link.DroppedAttributes,
+		})
+	}
+
+	resourceAttrs, err := decodeAttributes(r.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	stub.Resource = resource.NewWithAttributes(r.ResourceSchemaURL, resourceAttrs...)
+
+	return stub.Snapshot(), nil
+}
+
+// newSpanContextRecord converts a span context into its serialized form.
+func newSpanContextRecord(sc trace.SpanContext) spanContextRecord {
+	return spanContextRecord{
+		TraceID:    sc.TraceID().String(),
+		SpanID:     sc.SpanID().String(),
+		TraceFlags: byte(sc.TraceFlags()),
+		TraceState: sc.TraceState().String(),
+		Remote:     sc.IsRemote(),
+	}
+}
+
+// spanContext rebuilds the span context. Zero IDs, as used for the parent of a root span,
+// are allowed.
+func (r spanContextRecord) spanContext() (trace.SpanContext, error) {
+	var cfg trace.SpanContextConfig
+
+	if err := decodeHexID(cfg.TraceID[:], r.TraceID); err != nil {
+		return trace.SpanContext{}, err
+	}
+
+	if err := decodeHexID(cfg.SpanID[:], r.SpanID); err != nil {
+		return trace.SpanContext{}, err
+	}
+
+	state, err := trace.ParseTraceState(r.TraceState)
+	if err != nil {
+		return trace.SpanContext{}, err
+	}
+
+	cfg.TraceFlags = trace.TraceFlags(r.TraceFlags)
+	cfg.TraceState = state
+	cfg.Remote = r.Remote
+
+	return trace.NewSpanContext(cfg), nil
+}
+
+// decodeHexID decodes a hex encoded trace or span ID into dst.
+func decodeHexID(dst []byte, s string) error {
+	if hex.DecodedLen(len(s)) != len(dst) {
+		return fmt.Errorf("%w: %q", errors.ErrInvalidSpanRecord, s)
+	}
+
+	if _, err := hex.Decode(dst, []byte(s)); err != nil {
+		return fmt.Errorf("%w: %w", errors.ErrInvalidSpanRecord, err)
+	}
+
+	return nil
+}
+
+// newAttributeRecords converts attributes into their serialized form.
+func newAttributeRecords(attrs []attribute.KeyValue) ([]attributeRecord, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+
+	records := make([]attributeRecord, 0, len(attrs))
+
+	for _, kv := range attrs {
+		value, err := json.Marshal(kv.Value.AsInterface())
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, attributeRecord{
+			Key:   string(kv.Key),
+			Type:  kv.Value.Type().String(),
+			Value: value,
+		})
+	}
+
+	return records, nil
+}
+
+// decodeAttributes rebuilds attributes from their serialized form.
+func decodeAttributes(records []attributeRecord) ([]attribute.KeyValue, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(records))
+
+	for _, record := range records {
+		kv, err := record.keyValue()
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, kv)
+	}
+
+	return attrs, nil
+}
+
+// keyValue rebuilds the attribute according to its recorded type.
+func (r attributeRecord) keyValue() (attribute.KeyValue, error) {
+	switch r.Type {
+	case attribute.BOOL.String():
+		return decodeAttribute(r, attribute.Bool)
+	case attribute.INT64.String():
+		return decodeAttribute(r, attribute.Int64)
+	case attribute.FLOAT64.String():
+		return decodeAttribute(r, attribute.Float64)
+	case attribute.STRING.String():
+		return decodeAttribute(r, attribute.String)
+	case attribute.BOOLSLICE.String():
+		return decodeAttribute(r, attribute.BoolSlice)
+	case attribute.INT64SLICE.String():
+		return decodeAttribute(r, attribute.Int64Slice)
+	case attribute.FLOAT64SLICE.String():
+		return decodeAttribute(r, attribute.Float64Slice)
+	case attribute.STRINGSLICE.String():
+		return decodeAttribute(r, attribute.StringSlice)
+	default:
+		return attribute.KeyValue{}, fmt.Errorf("%w: attribute %q has type %q", errors.ErrInvalidSpanRecord, r.Key, r.Type)
+	}
+}
+
+// decodeAttribute unmarshals the record's value into T and builds the attribute with build.
+func decodeAttribute[T any](r attributeRecord, build func(string, T) attribute.KeyValue) (attribute.KeyValue, error) {
+	var value T
+
+	if err := json.Unmarshal(r.Value, &value); err != nil {
+		return attribute.KeyValue{}, fmt.Errorf("%w: attribute %q: %w", errors.ErrInvalidSpanRecord, r.Key, err)
+	}
+
+	return build(r.Key, value), nil
+}
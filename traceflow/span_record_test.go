@@ -0,0 +1,108 @@
+package traceflow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestSpanRecordRoundTrip tests that a span survives encoding to JSON and back.
+func TestSpanRecordRoundTrip(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 0, 0, 123, time.UTC)
+	state, err := trace.ParseTraceState("vendor=value")
+	require.NoError(t, err)
+
+	sc := testSpanContext(t).WithTraceState(state)
+	attrs := []attribute.KeyValue{
+		attribute.Bool("bool", true),
+		attribute.Int64("int", 1<<62+1),
+		attribute.Float64("float", 1.5),
+		attribute.String("string", "value"),
+		attribute.BoolSlice("bools", []bool{true, false}),
+		attribute.Int64Slice("ints", []int64{1, 2}),
+		attribute.Float64Slice("floats", []float64{0.5}),
+		attribute.StringSlice("strings", []string{"a", "b"}),
+	}
+
+	stub := tracetest.SpanStub{
+		Name:        "checkout",
+		SpanContext: sc,
+		SpanKind:    trace.SpanKindServer,
+		StartTime:   start,
+		EndTime:     start.Add(time.Second),
+		Attributes:  attrs,
+		Events: []sdktrace.Event{
+			{Name: "retry", Time: start, Attributes: attrs[:1], DroppedAttributeCount: 2},
+		},
+		Links: []sdktrace.Link{
+			{SpanContext: sc.WithRemote(true), Attributes: attrs[1:2]},
+		},
+		Status:               sdktrace.Status{Code: codes.Error, Description: "boom"},
+		DroppedAttributes:    1,
+		ChildSpanCount:       3,
+		Resource:             resource.NewWithAttributes("https://example.com/schema", attribute.String("service.name", "shop")),
+		InstrumentationScope: instrumentation.Scope{Name: "traceflow", Version: "1.0.0"},
+	}
+
+	record, err := newSpanRecord(stub.Snapshot())
+	require.NoError(t, err)
+
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+
+	var decoded spanRecord
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	span, err := decoded.snapshot()
+	require.NoError(t, err)
+
+	assert.Equal(t, tracetest.SpanStubFromReadOnlySpan(stub.Snapshot()), tracetest.SpanStubFromReadOnlySpan(span))
+	assert.False(t, span.Parent().IsValid(), "Expected the root span to keep an empty parent")
+}
+
+// TestSpanRecordInvalid tests that malformed records are rejected.
+func TestSpanRecordInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		record spanRecord
+	}{
+		{
+			name:   "invalid trace ID",
+			record: spanRecord{SpanContext: spanContextRecord{TraceID: "xyz", SpanID: "00f067aa0ba902b7"}},
+		},
+		{
+			name: "unknown attribute type",
+			record: spanRecord{
+				SpanContext: newSpanContextRecord(testSpanContext(t)),
+				Parent:      newSpanContextRecord(trace.SpanContext{}),
+				Attributes:  []attributeRecord{{Key: "k", Type: "MAP", Value: json.RawMessage(`{}`)}},
+			},
+		},
+		{
+			name: "mismatched attribute value",
+			record: spanRecord{
+				SpanContext: newSpanContextRecord(testSpanContext(t)),
+				Parent:      newSpanContextRecord(trace.SpanContext{}),
+				Attributes:  []attributeRecord{{Key: "k", Type: "INT64", Value: json.RawMessage(`"one"`)}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.record.snapshot()
+			assert.Error(t, err)
+		})
+	}
+}
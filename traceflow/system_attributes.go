@@ -14,7 +14,7 @@ import (
 
 // AddSystemInfo adds system-related attributes like hostname and environment.
 func (t *Trace) AddSystemInfo(hostname, ipAddress, environment string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("system.hostname", hostname),
 		attribute.String("system.ip_address", ipAddress),
 		attribute.String("system.environment", environment),
@@ -33,7 +33,7 @@ func (t *Trace) AddCPUInfo() *Trace {
 	cpuCount := runtime.NumCPU()
 	cpuArchitecture := runtime.GOARCH
 
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.Int("cpu.count", cpuCount),
 		attribute.String("cpu.architecture", cpuArchitecture),
 	)
@@ -61,7 +61,7 @@ func (t *Trace) AddMemoryInfo() *Trace {
 	var memStats runtime.MemStats
 
 	runtime.ReadMemStats(&memStats)
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.Int64("memory.total_alloc", safeUint64ToInt64(memStats.TotalAlloc)),
 		attribute.Int64("memory.sys", safeUint64ToInt64(memStats.Sys)),
 		attribute.Int64("memory.heap_alloc", safeUint64ToInt64(memStats.HeapAlloc)),
@@ -98,7 +98,7 @@ func (t *Trace) AddDiskInfo() *Trace {
 	totalDiskSpace := stat.Blocks * uint64(stat.Bsize)
 	freeDiskSpace := stat.Bfree * uint64(stat.Bsize)
 
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.Int64("disk.total", safeUint64ToInt64(totalDiskSpace)),
 		attribute.Int64("disk.free", safeUint64ToInt64(freeDiskSpace)),
 	)
@@ -119,7 +119,9 @@ func (t *Trace) AddDiskInfo() *Trace {
 //	trace.AddProcessInfo()
 //
 // Notes:
-// - If the command cannot be determined, it defaults to "unknown".
+//   - If the command cannot be determined, it defaults to "unknown".
+//   - Init already records the process on the resource once; use this method only when
+//     the process attributes are needed on the span itself.
 func (t *Trace) AddProcessInfo() *Trace {
 	processID := os.Getpid() // Gets the current process ID
 
@@ -128,7 +130,7 @@ func (t *Trace) AddProcessInfo() *Trace {
 		command = "unknown"
 	}
 
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.Int("process.id", processID),
 		attribute.String("process.command", command),
 	)
@@ -149,7 +151,9 @@ func (t *Trace) AddProcessInfo() *Trace {
 //	trace.AddContainerInfo()
 //
 // Notes:
-// - This method is designed to work in Docker or Kubernetes environments.
+//   - This method is designed to work in Docker or Kubernetes environments.
+//   - Init already records container.id on the resource once; use this method only when
+//     the container attributes are needed on the span itself.
 func (t *Trace) AddContainerInfo() *Trace {
 	// Get the container ID from the cgroup (works in Docker/Kubernetes)
 	containerID := "unknown"
@@ -174,7 +178,7 @@ func (t *Trace) AddContainerInfo() *Trace {
 		image = "unknown"
 	}
 
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("container.id", containerID),
 		attribute.String("container.image", image),
 	)
@@ -183,8 +187,10 @@ func (t *Trace) AddContainerInfo() *Trace {
 }
 
 // AddKubernetesInfo adds Kubernetes-related attributes like pod name and namespace.
+// Init already records the pod, namespace and node on the resource when they are exposed
+// through the downward API; see WithoutDetector.
 func (t *Trace) AddKubernetesInfo(podName, namespace string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("kubernetes.pod_name", podName),
 		attribute.String("kubernetes.namespace", namespace),
 	)
@@ -194,7 +200,7 @@ func (t *Trace) AddKubernetesInfo(podName, namespace string) *Trace {
 
 // AddNetworkInfo adds network-related attributes to the trace.
 func (t *Trace) AddNetworkInfo(protocol string, latency time.Duration) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("network.protocol", protocol),
 		attribute.Int64("network.latency_ms", latency.Milliseconds()),
 	)
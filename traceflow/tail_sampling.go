@@ -0,0 +1,439 @@
+package traceflow
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultTailDecisionWait is how long spans of a trace are buffered before it is sampled.
+	defaultTailDecisionWait = 10 * time.Second
+
+	// defaultTailMaxTraces is the default number of traces buffered at once.
+	defaultTailMaxTraces = 10000
+
+	// defaultTailMaxSpansPerTrace is the default number of spans buffered for one trace.
+	defaultTailMaxSpansPerTrace = 1000
+)
+
+// TailPolicy decides whether a trace is kept by tail sampling, given the spans buffered for
+// it during the decision window.
+type TailPolicy func(spans []sdktrace.ReadOnlySpan) bool
+
+// TailSamplingOption defines a functional option for configuring tail sampling with
+// WithTailSampling.
+type TailSamplingOption func(*tailSamplingConfig)
+
+// tailSamplingConfig holds the policies and limits of the tail sampling processor.
+type tailSamplingConfig struct {
+	policies         []TailPolicy
+	decisionWait     time.Duration
+	maxTraces        int
+	maxSpansPerTrace int
+}
+
+// WithTailSampling buffers finished spans per trace and only exports traces that match at
+// least one policy once the decision window has passed. Late spans of a trace follow the
+// decision made for it. Tail sampling needs every span to be recorded, so it is normally
+// combined with the default AlwaysSample sampler. At least one policy is required; Init
+// returns an error otherwise.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithTailSampling(
+//	        traceflow.TailSampleErrors(),
+//	        traceflow.TailSampleLatency(2*time.Second),
+//	        traceflow.TailSampleAttribute(attribute.String("tenant.tier", "enterprise")),
+//	        traceflow.TailSampleRatio(0.05),
+//	        traceflow.WithTailDecisionWait(5*time.Second),
+//	    ),
+//	)
+func WithTailSampling(opts ...TailSamplingOption) InitOption {
+	return func(tb *TelemetryBuilder) {
+		cfg := &tailSamplingConfig{
+			decisionWait:     defaultTailDecisionWait,
+			maxTraces:        defaultTailMaxTraces,
+			maxSpansPerTrace: defaultTailMaxSpansPerTrace,
+		}
+
+		for _, opt := range opts {
+			opt(cfg)
+		}
+
+		// Traces are only kept when a policy matches them, so without one every trace is lost
+		if len(cfg.policies) == 0 {
+			tb.addError(errors.ErrInvalidTailSampling, stderrors.New("no policy given"))
+			return
+		}
+
+		tb.tailSampling = cfg
+	}
+}
+
+// WithTailPolicy keeps traces for which policy returns true.
+func WithTailPolicy(policy TailPolicy) TailSamplingOption {
+	return func(c *tailSamplingConfig) {
+		c.policies = append(c.policies, policy)
+	}
+}
+
+// TailSampleErrors keeps traces containing a span with an error status.
+func TailSampleErrors() TailSamplingOption {
+	return WithTailPolicy(func(spans []sdktrace.ReadOnlySpan) bool {
+		for _, span := range spans {
+			if span.Status().Code == codes.Error {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// TailSampleLatency keeps traces that took at least threshold, from the start of their
+// first span to the end of their last one.
+func TailSampleLatency(threshold time.Duration) TailSamplingOption {
+	return WithTailPolicy(func(spans []sdktrace.ReadOnlySpan) bool {
+		var start, end time.Time
+
+		for _, span := range spans {
+			if start.IsZero() || span.StartTime().Before(start) {
+				start = span.StartTime()
+			}
+
+			if span.EndTime().After(end) {
+				end = span.EndTime()
+			}
+		}
+
+		return end.Sub(start) >= threshold
+	})
+}
+
+// TailSampleAttribute keeps traces containing a span with the given attribute.
+func TailSampleAttribute(kv attribute.KeyValue) TailSamplingOption {
+	return WithTailPolicy(func(spans []sdktrace.ReadOnlySpan) bool {
+		for _, span := range spans {
+			for _, attr := range span.Attributes() {
+				if attr == kv {
+					return true
+				}
+			}
+		}
+
+		return false
+	})
+}
+
+// TailSampleRatio keeps the given fraction of the remaining traces as a baseline, based on
+// the trace ID like WithTraceIDRatio.
+func TailSampleRatio(ratio float64) TailSamplingOption {
+	sampler := sdktrace.TraceIDRatioBased(ratio)
+
+	return WithTailPolicy(func(spans []sdktrace.ReadOnlySpan) bool {
+		if len(spans) == 0 {
+			return false
+		}
+
+		result := sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: spans[0].SpanContext().TraceID()})
+
+		return result.Decision == sdktrace.RecordAndSample
+	})
+}
+
+// WithTailDecisionWait sets how long the spans of a trace are buffered, from its first
+// finished span, before deciding whether to keep it. The default is 10 seconds.
+func WithTailDecisionWait(wait time.Duration) TailSamplingOption {
+	return func(c *tailSamplingConfig) {
+		if wait > 0 {
+			c.decisionWait = wait
+		}
+	}
+}
+
+// WithTailMaxTraces limits the number of traces buffered at once. When the limit is reached
+// the oldest trace is decided early. The default is 10000.
+func WithTailMaxTraces(n int) TailSamplingOption {
+	return func(c *tailSamplingConfig) {
+		if n > 0 {
+			c.maxTraces = n
+		}
+	}
+}
+
+// WithTailMaxSpansPerTrace limits the number of spans buffered for one trace. A trace that
+// reaches the limit is decided early. The default is 1000.
+func WithTailMaxSpansPerTrace(n int) TailSamplingOption {
+	return func(c *tailSamplingConfig) {
+		if n > 0 {
+			c.maxSpansPerTrace = n
+		}
+	}
+}
+
+// tailTrace holds the spans buffered for a trace.
+type tailTrace struct {
+	spans    []sdktrace.ReadOnlySpan
+	deadline time.Time
+}
+
+// tailEntry records when a buffered trace is due for a decision.
+type tailEntry struct {
+	id       trace.TraceID
+	deadline time.Time
+}
+
+// tailSamplingProcessor buffers finished spans per trace and passes the spans of kept traces
+// on to the exporters' processors.
+type tailSamplingProcessor struct {
+	config tailSamplingConfig
+	next   []sdktrace.SpanProcessor
+	now    func() time.Time
+
+	mu      sync.Mutex
+	traces  map[trace.TraceID]*tailTrace
+	pending []tailEntry
+
+	// decided remembers recent decisions so that late spans follow them
+	decided      map[trace.TraceID]bool
+	decidedOrder []trace.TraceID
+
+	shutdownOnce sync.Once
+	done         chan struct{}
+	stopped      chan struct{}
+}
+
+// newTailSamplingProcessor creates a tail sampling processor feeding next and starts
+// deciding traces as their window passes.
+func newTailSamplingProcessor(cfg tailSamplingConfig, next ...sdktrace.SpanProcessor) *tailSamplingProcessor {
+	p := &tailSamplingProcessor{
+		config:  cfg,
+		next:    next,
+		now:     time.Now,
+		traces:  make(map[trace.TraceID]*tailTrace),
+		decided: make(map[trace.TraceID]bool),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go p.run()
+
+	return p
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (p *tailSamplingProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	for _, next := range p.next {
+		next.OnStart(parent, s)
+	}
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *tailSamplingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+
+	id := s.SpanContext().TraceID()
+
+	p.mu.Lock()
+
+	if keep, ok := p.decided[id]; ok {
+		p.mu.Unlock()
+
+		if keep {
+			p.forward([]sdktrace.ReadOnlySpan{s})
+		}
+
+		return
+	}
+
+	var kept []sdktrace.ReadOnlySpan
+
+	buffered, ok := p.traces[id]
+	if !ok {
+		// Make room by deciding the oldest trace early
+		if len(p.traces) >= p.config.maxTraces {
+			kept = append(kept, p.decideOldest()...)
+		}
+
+		buffered = &tailTrace{deadline: p.now().Add(p.config.decisionWait)}
+		p.traces[id] = buffered
+		p.pending = append(p.pending, tailEntry{id: id, deadline: buffered.deadline})
+	}
+
+	buffered.spans = append(buffered.spans, s)
+
+	if len(buffered.spans) >= p.config.maxSpansPerTrace {
+		kept = append(kept, p.decide(id, buffered)...)
+	}
+
+	p.mu.Unlock()
+
+	p.forward(kept)
+}
+
+// ForceFlush decides every buffered trace and flushes the exporters' processors.
+func (p *tailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	p.forward(p.decideAll())
+
+	var errs []error
+
+	for _, next := range p.next {
+		if err := next.ForceFlush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return stderrors.Join(errs...)
+}
+
+// Shutdown stops the decision loop, decides every buffered trace and shuts down the
+// exporters' processors.
+func (p *tailSamplingProcessor) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	p.shutdownOnce.Do(func() {
+		close(p.done)
+		<-p.stopped
+
+		p.forward(p.decideAll())
+
+		for _, next := range p.next {
+			if err := next.Shutdown(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	return stderrors.Join(errs...)
+}
+
+// run decides traces whose window has passed until the processor is shut down.
+func (p *tailSamplingProcessor) run() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(max(p.config.decisionWait/2, time.Millisecond)) //nolint:mnd
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.forward(p.decideExpired())
+		}
+	}
+}
+
+// decideExpired decides the traces whose window has passed and returns the spans to keep.
+func (p *tailSamplingProcessor) decideExpired() []sdktrace.ReadOnlySpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var kept []sdktrace.ReadOnlySpan
+
+	now := p.now()
+
+	for len(p.pending) > 0 {
+		entry := p.pending[0]
+
+		// Skip entries of traces that were already decided early
+		buffered, ok := p.traces[entry.id]
+		if !ok || !buffered.deadline.Equal(entry.deadline) {
+			p.pending = p.pending[1:]
+			continue
+		}
+
+		if entry.deadline.After(now) {
+			break
+		}
+
+		p.pending = p.pending[1:]
+		kept = append(kept, p.decide(entry.id, buffered)...)
+	}
+
+	return kept
+}
+
+// decideAll decides every buffered trace and returns the spans to keep.
+func (p *tailSamplingProcessor) decideAll() []sdktrace.ReadOnlySpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var kept []sdktrace.ReadOnlySpan
+
+	for id, buffered := range p.traces {
+		kept = append(kept, p.decide(id, buffered)...)
+	}
+
+	p.pending = nil
+
+	return kept
+}
+
+// decideOldest decides the trace that has been buffered the longest. The caller must hold
+// p.mu.
+func (p *tailSamplingProcessor) decideOldest() []sdktrace.ReadOnlySpan {
+	for len(p.pending) > 0 {
+		entry := p.pending[0]
+		p.pending = p.pending[1:]
+
+		if buffered, ok := p.traces[entry.id]; ok && buffered.deadline.Equal(entry.deadline) {
+			return p.decide(entry.id, buffered)
+		}
+	}
+
+	return nil
+}
+
+// decide applies the policies to a buffered trace, remembers the decision and returns the
+// spans to keep. The caller must hold p.mu.
+func (p *tailSamplingProcessor) decide(id trace.TraceID, buffered *tailTrace) []sdktrace.ReadOnlySpan {
+	delete(p.traces, id)
+
+	keep := false
+
+	for _, policy := range p.config.policies {
+		if policy(buffered.spans) {
+			keep = true
+			break
+		}
+	}
+
+	// Remember as many decisions as traces may be buffered
+	if len(p.decidedOrder) >= p.config.maxTraces {
+		delete(p.decided, p.decidedOrder[0])
+		p.decidedOrder = p.decidedOrder[1:]
+	}
+
+	p.decided[id] = keep
+	p.decidedOrder = append(p.decidedOrder, id)
+
+	if !keep {
+		return nil
+	}
+
+	return buffered.spans
+}
+
+// forward passes the kept spans to the exporters' processors.
+func (p *tailSamplingProcessor) forward(spans []sdktrace.ReadOnlySpan) {
+	for _, span := range spans {
+		for _, next := range p.next {
+			next.OnEnd(span)
+		}
+	}
+}
+
+// Ensure tailSamplingProcessor implements sdktrace.SpanProcessor
+var _ sdktrace.SpanProcessor = (*tailSamplingProcessor)(nil)
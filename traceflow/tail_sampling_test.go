@@ -0,0 +1,212 @@
+package traceflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tailSpans builds finished spans of one trace from stubs, filling in a sampled span context.
+func tailSpans(t *testing.T, traceByte byte, stubs ...tracetest.SpanStub) []sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for i := range stubs {
+		stubs[i].SpanContext = trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{traceByte},
+			SpanID:     trace.SpanID{byte(i + 1)},
+			TraceFlags: trace.FlagsSampled,
+		})
+	}
+
+	return tracetest.SpanStubs(stubs).Snapshots()
+}
+
+// TestTailPolicies tests the built-in tail sampling policies.
+func TestTailPolicies(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name   string
+		option TailSamplingOption
+		spans  []tracetest.SpanStub
+		keep   bool
+	}{
+		{
+			name:   "error kept",
+			option: TailSampleErrors(),
+			spans:  []tracetest.SpanStub{{}, {Status: sdktrace.Status{Code: codes.Error}}},
+			keep:   true,
+		},
+		{
+			name:   "no error dropped",
+			option: TailSampleErrors(),
+			spans:  []tracetest.SpanStub{{Status: sdktrace.Status{Code: codes.Ok}}},
+		},
+		{
+			name:   "slow trace kept",
+			option: TailSampleLatency(time.Second),
+			spans: []tracetest.SpanStub{
+				{StartTime: start, EndTime: start.Add(100 * time.Millisecond)},
+				{StartTime: start.Add(900 * time.Millisecond), EndTime: start.Add(time.Second)},
+			},
+			keep: true,
+		},
+		{
+			name:   "fast trace dropped",
+			option: TailSampleLatency(time.Second),
+			spans:  []tracetest.SpanStub{{StartTime: start, EndTime: start.Add(999 * time.Millisecond)}},
+		},
+		{
+			name:   "attribute match kept",
+			option: TailSampleAttribute(attribute.String("tenant", "vip")),
+			spans:  []tracetest.SpanStub{{}, {Attributes: []attribute.KeyValue{attribute.String("tenant", "vip")}}},
+			keep:   true,
+		},
+		{
+			name:   "attribute mismatch dropped",
+			option: TailSampleAttribute(attribute.String("tenant", "vip")),
+			spans:  []tracetest.SpanStub{{Attributes: []attribute.KeyValue{attribute.String("tenant", "free")}}},
+		},
+		{
+			name:   "ratio one kept",
+			option: TailSampleRatio(1),
+			spans:  []tracetest.SpanStub{{}},
+			keep:   true,
+		},
+		{
+			name:   "ratio zero dropped",
+			option: TailSampleRatio(0),
+			spans:  []tracetest.SpanStub{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg tailSamplingConfig
+
+			tt.option(&cfg)
+			require.Len(t, cfg.policies, 1)
+
+			assert.Equal(t, tt.keep, cfg.policies[0](tailSpans(t, 1, tt.spans...)))
+		})
+	}
+}
+
+// TestTailSamplingKeepsWholeTraces tests that every span of a matching trace is exported and
+// that other traces are dropped.
+func TestTailSamplingKeepsWholeTraces(t *testing.T) {
+	spans := initRecording(t, WithTailSampling(TailSampleErrors()))
+
+	failed := New(context.Background(), "test-service").Start("failed")
+	failed.StartChild("step").End()
+	failed.SetStatus(codes.Error, "boom")
+	failed.End()
+
+	ok := New(context.Background(), "test-service").Start("ok")
+	ok.StartChild("step").End()
+	ok.End()
+
+	names := make([]string, 0, 2)
+	for _, span := range spans() {
+		names = append(names, span.Name)
+	}
+
+	assert.ElementsMatch(t, []string{"test-service.failed", "test-service.step"}, names)
+}
+
+// TestTailSamplingWithoutPolicy tests that tail sampling without a policy, which would drop
+// every trace, is rejected.
+func TestTailSamplingWithoutPolicy(t *testing.T) {
+	_, _, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithTailSampling(WithTailDecisionWait(time.Second)),
+	)
+
+	assert.ErrorIs(t, err, errors.ErrInvalidTailSampling)
+}
+
+// newTestTailProcessor creates a tail sampling processor recording kept spans in memory.
+func newTestTailProcessor(t *testing.T, opts ...TailSamplingOption) (*tailSamplingProcessor, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	cfg := tailSamplingConfig{
+		decisionWait:     time.Hour,
+		maxTraces:        defaultTailMaxTraces,
+		maxSpansPerTrace: defaultTailMaxSpansPerTrace,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newTailSamplingProcessor(cfg, sdktrace.NewSimpleSpanProcessor(exporter))
+
+	t.Cleanup(func() { processor.Shutdown(context.Background()) })
+
+	return processor, exporter
+}
+
+// TestTailSamplingDecisionWindow tests that traces are decided once their window has passed
+// and that late spans follow the decision.
+func TestTailSamplingDecisionWindow(t *testing.T) {
+	processor, exporter := newTestTailProcessor(t, TailSampleErrors(), WithTailDecisionWait(20*time.Millisecond))
+
+	kept := tailSpans(t, 1, tracetest.SpanStub{Name: "failed", Status: sdktrace.Status{Code: codes.Error}}, tracetest.SpanStub{Name: "late"})
+	dropped := tailSpans(t, 2, tracetest.SpanStub{Name: "ok"}, tracetest.SpanStub{Name: "late"})
+
+	processor.OnEnd(kept[0])
+	processor.OnEnd(dropped[0])
+
+	assert.Empty(t, exporter.GetSpans(), "Expected spans to be held until the decision")
+
+	assert.Eventually(t, func() bool {
+		return len(exporter.GetSpans()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	processor.OnEnd(kept[1])
+	processor.OnEnd(dropped[1])
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "failed", spans[0].Name)
+	assert.Equal(t, "late", spans[1].Name)
+	assert.Equal(t, kept[1].SpanContext().TraceID(), spans[1].SpanContext.TraceID())
+}
+
+// TestTailSamplingMemoryBounds tests that traces are decided early once the buffer limits
+// are reached.
+func TestTailSamplingMemoryBounds(t *testing.T) {
+	processor, exporter := newTestTailProcessor(t,
+		TailSampleRatio(1),
+		WithTailMaxTraces(2),
+		WithTailMaxSpansPerTrace(3),
+	)
+
+	for i := byte(1); i <= 3; i++ {
+		processor.OnEnd(tailSpans(t, i, tracetest.SpanStub{Name: "root"})[0])
+	}
+
+	if spans := exporter.GetSpans(); assert.Len(t, spans, 1, "Expected the oldest trace to be decided") {
+		assert.Equal(t, trace.TraceID{1}, spans[0].SpanContext.TraceID())
+	}
+
+	exporter.Reset()
+
+	for _, span := range tailSpans(t, 4, tracetest.SpanStub{}, tracetest.SpanStub{}, tracetest.SpanStub{}) {
+		processor.OnEnd(span)
+	}
+
+	// The new trace displaced trace 2 and was then decided as soon as it held three spans
+	assert.Len(t, exporter.GetSpans(), 4)
+	assert.Len(t, processor.traces, 1)
+}
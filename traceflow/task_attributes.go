@@ -6,19 +6,43 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
-// AddEvent adds an event name and timestamp as an attribute to the trace.
-func (t *Trace) AddEvent(eventName string, timestamp time.Time) *Trace {
-	t.attrs = append(t.attrs,
-		attribute.String("event.name", eventName),
-		attribute.String("event.timestamp", timestamp.String()),
-	)
+// AddEvent records a span event with the given name, timestamp, and optional attributes.
+// Events appear on the span's timeline in tracing backends, and multiple events can be
+// recorded on the same span without overwriting each other.
+//
+// If the span has not been started yet, the event is buffered and emitted when Start is
+// called, keeping its original timestamp.
+//
+// Example usage:
+//
+//	trace.AddEvent("cache.miss", time.Now(), traceflow.AddString("cache.key", key))
+func (t *Trace) AddEvent(eventName string, timestamp time.Time, attrs ...Attribute) *Trace {
+	event := spanEvent{
+		name:      eventName,
+		timestamp: timestamp,
+		attrs:     make([]attribute.KeyValue, 0, len(attrs)),
+	}
+
+	for _, attr := range attrs {
+		event.attrs = append(event.attrs, attr.otelAttr)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.span != nil {
+		t.span.AddEvent(event.name, event.options()...)
+		return t
+	}
+
+	t.events = append(t.events, event)
 
 	return t
 }
 
 // AddTaskInfo adds task-related information to the trace.
 func (t *Trace) AddTaskInfo(taskID, taskName string, retries int) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("task.id", taskID),
 		attribute.String("task.name", taskName),
 		attribute.Int("task.retries", retries),
@@ -29,7 +53,7 @@ func (t *Trace) AddTaskInfo(taskID, taskName string, retries int) *Trace {
 
 // AddUser adds user-related attributes to the trace.
 func (t *Trace) AddUser(userID, username string) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("user.id", userID),
 		attribute.String("user.username", username),
 	)
@@ -39,7 +63,7 @@ func (t *Trace) AddUser(userID, username string) *Trace {
 
 // AddCustomMetric adds a custom metric to the trace.
 func (t *Trace) AddCustomMetric(metricName string, value float64) *Trace {
-	t.attrs = append(t.attrs,
+	t.setAttributes(
 		attribute.String("metric.name", metricName),
 		attribute.Float64("metric.value", value),
 	)
@@ -8,23 +8,49 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
-// TestAddEvent tests the AddEvent method.
+// TestAddEvent tests that AddEvent records span events rather than attributes.
 func TestAddEvent(t *testing.T) {
-	ctx := context.TODO()
-	trace := New(ctx, "test-service")
+	trace, exporter := newRecordedTrace(t)
 
-	// Use a fixed time for the test
-	eventTime := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
-	trace.AddEvent("test-event", eventTime)
+	// Use fixed times for the test
+	bufferedTime := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	liveTime := bufferedTime.Add(time.Second)
 
-	if len(trace.attrs) != 2 {
-		t.Fatalf("Expected 2 attributes, got %d", len(trace.attrs))
+	trace.AddEvent("buffered-event", bufferedTime)
+
+	if len(trace.attrs) != 0 {
+		t.Fatalf("Expected no attributes, got %d", len(trace.attrs))
+	}
+
+	trace.Start("events")
+	trace.AddEvent("live-event", liveTime, AddString("cache.key", "user:1"))
+	trace.AddEvent("live-event", liveTime.Add(time.Second))
+	trace.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 exported span, got %d", len(spans))
 	}
-	if trace.attrs[0] != attribute.String("event.name", "test-event") {
-		t.Errorf("Expected event.name to be 'test-event', got %v", trace.attrs[0])
+
+	events := spans[0].Events
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	if events[0].Name != "buffered-event" || !events[0].Time.Equal(bufferedTime) {
+		t.Errorf("Expected buffered-event at %s, got %s at %s", bufferedTime, events[0].Name, events[0].Time)
+	}
+
+	if events[1].Name != "live-event" || !events[1].Time.Equal(liveTime) {
+		t.Errorf("Expected live-event at %s, got %s at %s", liveTime, events[1].Name, events[1].Time)
+	}
+
+	if len(events[1].Attributes) != 1 || events[1].Attributes[0] != attribute.String("cache.key", "user:1") {
+		t.Errorf("Expected cache.key attribute on live-event, got %v", events[1].Attributes)
 	}
-	if trace.attrs[1] != attribute.String("event.timestamp", eventTime.String()) {
-		t.Errorf("Expected event.timestamp to be '%s', got %v", eventTime.String(), trace.attrs[1])
+
+	if len(events[2].Attributes) != 0 {
+		t.Errorf("Expected no attributes on second live-event, got %v", events[2].Attributes)
 	}
 }
 
@@ -0,0 +1,127 @@
+package traceflow
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	metricapi "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Telemetry is a handle to a tracer provider, propagator and meter provider that are not
+// installed globally. Traces created with its methods use its own provider and propagator,
+// so several Telemetry instances, for example one per tenant or one per parallel test, can
+// run side by side in one process. A Telemetry is safe for concurrent use.
+//
+// The package-level functions, such as New and Now, use a default Telemetry that follows
+// the global OpenTelemetry providers installed by Init.
+type Telemetry struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+	meterProvider  metricapi.MeterProvider
+	queue          *spanQueue
+	disabled       bool
+
+	shutdownOnce sync.Once
+	shutdown     func(context.Context) error
+	shutdownErr  error
+}
+
+// defaultTelemetry backs the package-level functions. Its nil providers resolve to the
+// global ones each time they are used, so it picks up providers installed after import.
+var defaultTelemetry = &Telemetry{}
+
+// NewTelemetry builds a tracer provider, and a meter provider if WithMetrics is given,
+// from the same options as Init, without installing them globally. Use the returned
+// Telemetry to create traces and shut it down when done.
+//
+// Example usage:
+//
+//	tel, err := traceflow.NewTelemetry(ctx, "billing", traceflow.WithOLTP("otel:4317"))
+//	if err != nil {
+//	    log.Fatalf("Failed to initialize OpenTelemetry: %v", err)
+//	}
+//	defer tel.Shutdown(ctx)
+//
+//	trace := tel.New(ctx, "billing").Start("charge")
+//	defer trace.End()
+func NewTelemetry(ctx context.Context, serviceName string, opts ...InitOption) (*Telemetry, error) {
+	builder, err := newTelemetryBuilder(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.build(serviceName)
+}
+
+// NewTelemetryWithProvider returns a Telemetry that uses an existing tracer provider and
+// propagator, such as providers configured by another library or a test recorder. A nil
+// propagator uses the global one. Shutting down the Telemetry does not shut down the
+// provider.
+func NewTelemetryWithProvider(tp trace.TracerProvider, propagator propagation.TextMapPropagator) *Telemetry {
+	return &Telemetry{tracerProvider: tp, propagator: propagator}
+}
+
+// TracerProvider returns the tracer provider used by the Telemetry.
+func (tel *Telemetry) TracerProvider() trace.TracerProvider {
+	if tel == nil || tel.tracerProvider == nil {
+		return otel.GetTracerProvider()
+	}
+
+	return tel.tracerProvider
+}
+
+// Propagator returns the propagator used to inject and extract trace context.
+func (tel *Telemetry) Propagator() propagation.TextMapPropagator {
+	if tel == nil || tel.propagator == nil {
+		return otel.GetTextMapPropagator()
+	}
+
+	return tel.propagator
+}
+
+// MeterProvider returns the meter provider of the Telemetry, or the global meter provider
+// if metrics were not enabled.
+func (tel *Telemetry) MeterProvider() metricapi.MeterProvider {
+	if tel == nil || tel.meterProvider == nil {
+		return otel.GetMeterProvider()
+	}
+
+	return tel.meterProvider
+}
+
+// Shutdown flushes and shuts down the providers created by NewTelemetry. It is a no-op for
+// the default Telemetry and for providers passed to NewTelemetryWithProvider. The providers
+// are shut down once; later calls return the result of the first.
+func (tel *Telemetry) Shutdown(ctx context.Context) error {
+	if tel == nil || tel.shutdown == nil {
+		return nil
+	}
+
+	tel.shutdownOnce.Do(func() {
+		tel.shutdownErr = tel.shutdown(ctx)
+	})
+
+	return tel.shutdownErr
+}
+
+// tracer returns a tracer for the service from the Telemetry's tracer provider.
+func (tel *Telemetry) tracer(service string) trace.Tracer {
+	return tel.TracerProvider().Tracer(service)
+}
+
+// WithTelemetry makes the trace use the tracer provider and propagator of tel instead of
+// the global ones. It is useful where traces are created for you, such as in Middleware,
+// Transport, the gRPC interceptors, Run and Do.
+//
+// Example usage:
+//
+//	handler := traceflow.Middleware("billing", traceflow.WithTraceOptions(traceflow.WithTelemetry(tel)))(mux)
+func WithTelemetry(tel *Telemetry) Option {
+	return func(t *Trace) {
+		t.telemetry = tel
+		t.tracer = tel.tracer(t.service)
+	}
+}
@@ -0,0 +1,152 @@
+package traceflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// TestNewTelemetryIsolation tests that Telemetry instances export to their own exporters
+// with their own resources, without touching the global providers.
+func TestNewTelemetryIsolation(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	for _, service := range []string{"tenant-a", "tenant-b"} {
+		t.Run(service, func(t *testing.T) {
+			t.Parallel()
+
+			exporter := tracetest.NewInMemoryExporter()
+
+			tel, err := NewTelemetry(context.Background(), service, WithSilentLogger(), WithSpanExporter(exporter))
+			require.NoError(t, err)
+
+			t.Cleanup(func() { tel.Shutdown(context.Background()) })
+
+			tel.New(context.Background(), service).Start("work").StartChild("step").End()
+			require.NoError(t, tel.Flush(context.Background()))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, service+".step", spans[0].Name)
+
+			name, ok := spans[0].Resource.Set().Value(semconv.ServiceNameKey)
+			assert.True(t, ok)
+			assert.Equal(t, service, name.AsString())
+		})
+	}
+
+	t.Cleanup(func() {
+		assert.Equal(t, previous, otel.GetTracerProvider(), "Expected the global tracer provider to be untouched")
+		assert.Equal(t, previousPropagator, otel.GetTextMapPropagator(), "Expected the global propagator to be untouched")
+	})
+}
+
+// TestTelemetryPropagator tests that traces created with a Telemetry inject and extract
+// trace context with its propagator rather than the global one.
+func TestTelemetryPropagator(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	tel, err := NewTelemetry(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithSpanExporter(exporter),
+		WithPropagators("b3"),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { tel.Shutdown(context.Background()) })
+
+	client := tel.Now(context.Background(), "client", "call")
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	client.InjectHTTPContext(req)
+	client.End()
+
+	assert.NotEmpty(t, req.Header.Get("b3"))
+	assert.Empty(t, req.Header.Get("traceparent"))
+
+	handler := Middleware("server", WithTraceOptions(WithTelemetry(tel)))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NoError(t, tel.Flush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, client.GetTraceID(), spans[1].SpanContext.TraceID().String(), "Expected the server span to continue the client trace")
+}
+
+// TestNewTelemetryWithProvider tests wrapping an existing provider, with a nil propagator
+// falling back to the global one.
+func TestNewTelemetryWithProvider(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tel := NewTelemetryWithProvider(tp, nil)
+
+	assert.Same(t, tp, tel.TracerProvider())
+	assert.Equal(t, otel.GetTextMapPropagator(), tel.Propagator())
+	assert.NoError(t, tel.Shutdown(context.Background()), "Expected Shutdown to leave the provider to its owner")
+
+	trace := tel.New(context.Background(), "test-service").Start("op")
+	trace.Child(WithAttributes(AddString("k", "v"))).Start("child").End()
+	trace.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID(), "Expected the child to use the Telemetry's provider")
+}
+
+// TestTelemetryCarriers tests that gRPC and message queue propagation use the propagator of
+// the Telemetry, so that instances with different propagators do not read each other's
+// headers.
+func TestTelemetryCarriers(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+
+	b3 := NewTelemetryWithProvider(tp, B3Propagator{})
+	w3c := NewTelemetryWithProvider(tp, propagation.TraceContext{})
+
+	trace := b3.New(context.Background(), "producer").Start("publish")
+	defer trace.End()
+
+	ctx := trace.GetContext()
+	want := trace.GetTraceID()
+
+	traceID := func(ctx context.Context) string {
+		return oteltrace.SpanContextFromContext(ctx).TraceID().String()
+	}
+
+	natsHeaders := nats.Header{}
+	b3.PropagateNats(ctx, natsHeaders)
+	assert.NotEmpty(t, propagation.HeaderCarrier(natsHeaders).Get("b3"))
+	assert.Equal(t, want, traceID(b3.ExtractNats(context.Background(), natsHeaders)))
+	assert.False(t, oteltrace.SpanContextFromContext(w3c.ExtractNats(context.Background(), natsHeaders)).IsValid())
+
+	var kafkaHeaders []kafka.Header
+
+	b3.PropagateKafka(ctx, &kafkaHeaders)
+	assert.Equal(t, want, traceID(b3.ExtractKafka(context.Background(), kafkaHeaders)))
+	assert.False(t, oteltrace.SpanContextFromContext(w3c.ExtractKafka(context.Background(), kafkaHeaders)).IsValid())
+
+	rabbitHeaders := amqp.Table{}
+	b3.PropagateRabbitMQ(ctx, rabbitHeaders)
+	assert.Contains(t, rabbitHeaders, "b3")
+	assert.Equal(t, want, traceID(b3.ExtractRabbitMQ(context.Background(), rabbitHeaders)))
+	assert.False(t, oteltrace.SpanContextFromContext(w3c.ExtractRabbitMQ(context.Background(), rabbitHeaders)).IsValid())
+
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs("b3", propagation.HeaderCarrier(natsHeaders).Get("b3")))
+	assert.Equal(t, want, traceID(b3.ExtractGRPCContext(incoming)))
+	assert.False(t, oteltrace.SpanContextFromContext(w3c.ExtractGRPCContext(incoming)).IsValid())
+}
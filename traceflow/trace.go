@@ -5,15 +5,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// Trace is a struct that holds the context, tracer, span, and attributes for a trace
+// Trace is a struct that holds the context, tracer, span, and attributes for a trace.
+// A Trace is safe for concurrent use by multiple goroutines.
 type Trace struct {
+	mu           sync.Mutex
 	ctx          context.Context
 	service      string
 	tracer       trace.Tracer
@@ -23,6 +25,8 @@ type Trace struct {
 	options      []trace.SpanStartOption
 	spanKind     *SpanKind
 	links        []trace.Link
+	events       []spanEvent
+	telemetry    *Telemetry
 }
 
 // New creates a new Trace object using the specified tracer from the OpenTelemetry provider.
@@ -53,6 +57,17 @@ type Trace struct {
 // - The options allow flexibility in configuring the Trace object during initialization.
 // - You can create multiple options to fit various use cases and simplify tracing setup.
 func New(ctx context.Context, spanName string, opts ...Option) *Trace {
+	return defaultTelemetry.New(ctx, spanName, opts...)
+}
+
+// New creates a new Trace, as the package-level New does, using the tracer provider and
+// propagator of the Telemetry.
+//
+// Example usage:
+//
+//	trace := tel.New(ctx, "my-service").Start("operation_name")
+//	defer trace.End()
+func (tel *Telemetry) New(ctx context.Context, spanName string, opts ...Option) *Trace {
 	var traceCtx context.Context
 
 	if ctx == nil {
@@ -71,11 +86,12 @@ func New(ctx context.Context, spanName string, opts ...Option) *Trace {
 	t := &Trace{
 		ctx:          traceCtx,
 		service:      spanName,
-		tracer:       otel.GetTracerProvider().Tracer(spanName),
+		tracer:       tel.tracer(spanName),
 		parentSpanID: parentSpanID,
 		attrs:        []attribute.KeyValue{},
 		options:      []trace.SpanStartOption{},
 		spanKind:     &SpanKind{option: trace.WithSpanKind(trace.SpanKindInternal)},
+		telemetry:    tel,
 	}
 
 	// Apply variadic options
@@ -97,15 +113,23 @@ func New(ctx context.Context, spanName string, opts ...Option) *Trace {
 //
 //	// The new trace will not be linked to the parent trace.
 func NewWithoutPropagation(ctx context.Context, spanName string, opts ...Option) *Trace {
+	return defaultTelemetry.NewWithoutPropagation(ctx, spanName, opts...)
+}
+
+// NewWithoutPropagation creates a new Trace that does not continue the trace in ctx, as
+// the package-level NewWithoutPropagation does, using the tracer provider and propagator
+// of the Telemetry.
+func (tel *Telemetry) NewWithoutPropagation(ctx context.Context, spanName string, opts ...Option) *Trace {
 	traceCtx := context.Background()
 
 	t := &Trace{
-		ctx:      traceCtx,
-		service:  spanName,
-		tracer:   otel.GetTracerProvider().Tracer(spanName),
-		attrs:    []attribute.KeyValue{},
-		options:  []trace.SpanStartOption{},
-		spanKind: &SpanKind{},
+		ctx:       traceCtx,
+		service:   spanName,
+		tracer:    tel.tracer(spanName),
+		attrs:     []attribute.KeyValue{},
+		options:   []trace.SpanStartOption{},
+		spanKind:  &SpanKind{},
+		telemetry: tel,
 	}
 
 	// Apply variadic options (if any)
@@ -117,8 +141,8 @@ func NewWithoutPropagation(ctx context.Context, spanName string, opts ...Option)
 }
 
 // Start creates a new span within the existing trace using the provided name.
-// It includes any attributes, links, and options that have been set on the trace.
-// After the span is created, attributes, links, and options are cleared to avoid
+// It includes any attributes, links, events, and options that have been set on the trace.
+// After the span is created, attributes, links, events, and options are cleared to avoid
 // accidental reuse in future spans.
 //
 // If a span kind (e.g., server, client) has been set, it will also be applied to
@@ -133,9 +157,12 @@ func NewWithoutPropagation(ctx context.Context, spanName string, opts ...Option)
 // - This method formats the operation name as "<service>.<name>".
 // - Once a span is started, it must be ended using the End method.
 func (t *Trace) Start(name string) *Trace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	// Ensure a valid tracer exists
 	if t.tracer == nil {
-		t.tracer = otel.GetTracerProvider().Tracer(t.service)
+		t.tracer = t.telemetry.tracer(t.service)
 	}
 
 	// Apply attributes if they exist
@@ -149,15 +176,23 @@ func (t *Trace) Start(name string) *Trace {
 	}
 
 	// Apply span kind if it exists
-	t.options = append(t.options, t.spanKind.option)
+	if t.spanKind != nil && t.spanKind.option != nil {
+		t.options = append(t.options, t.spanKind.option)
+	}
 
 	// Start the span
 	operation := fmt.Sprintf("%s.%s", t.service, name)
 	t.ctx, t.span = t.tracer.Start(trace.ContextWithSpan(t.ctx, trace.SpanFromContext(t.ctx)), operation, t.options...)
 
-	// Clear attributes, links, and options after starting the span to avoid re-use
+	// Emit any events recorded before the span started
+	for _, event := range t.events {
+		t.span.AddEvent(event.name, event.options()...)
+	}
+
+	// Clear attributes, links, events, and options after starting the span to avoid re-use
 	t.attrs = nil
 	t.links = nil
+	t.events = nil
 	t.options = nil
 
 	return t
@@ -165,13 +200,80 @@ func (t *Trace) Start(name string) *Trace {
 
 // Now creates and starts a trace with options immediately.
 func Now(ctx context.Context, name, operation string, opts ...Option) *Trace {
-	trace := New(ctx, name, opts...)
+	return defaultTelemetry.Now(ctx, name, operation, opts...)
+}
+
+// Now creates and starts a trace with options immediately, using the tracer provider and
+// propagator of the Telemetry.
+func (tel *Telemetry) Now(ctx context.Context, name, operation string, opts ...Option) *Trace {
+	trace := tel.New(ctx, name, opts...)
 
 	trace.Start(operation)
 
 	return trace
 }
 
+// Child creates a new Trace parented to the current span. The child inherits the
+// service name, tracer, Telemetry, and span kind of its parent, and any options supplied are
+// applied on top of those defaults. If the parent has not been started yet, the
+// child is parented to whatever span is present in the parent's context.
+//
+// Example usage:
+//
+//	parent := traceflow.New(ctx, "my-service").Server().Start("handle-request")
+//	defer parent.End()
+//
+//	child := parent.Child(traceflow.WithAttributes(traceflow.AddString("step", "validate")))
+//	defer child.Start("validate").End()
+//
+// Notes:
+//   - The child's span kind can be overridden with Server, Client, Producer or Consumer
+//     before it is started.
+func (t *Trace) Child(opts ...Option) *Trace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var parentSpanID string
+	if span := trace.SpanFromContext(t.ctx); span.SpanContext().IsValid() {
+		parentSpanID = span.SpanContext().SpanID().String()
+	}
+
+	child := &Trace{
+		ctx:          t.ctx,
+		service:      t.service,
+		tracer:       t.tracer,
+		parentSpanID: parentSpanID,
+		attrs:        []attribute.KeyValue{},
+		options:      []trace.SpanStartOption{},
+		spanKind:     &SpanKind{},
+		telemetry:    t.telemetry,
+	}
+
+	if t.spanKind != nil {
+		child.spanKind.option = t.spanKind.option
+	}
+
+	for _, opt := range opts {
+		opt(child)
+	}
+
+	return child
+}
+
+// StartChild creates and starts a new span parented to the current span, returning
+// the child Trace. It is shorthand for t.Child().Start(name) and allows span trees
+// to be built fluently.
+//
+// Example usage:
+//
+//	trace := traceflow.New(ctx, "my-service").Start("checkout")
+//	defer trace.End()
+//
+//	defer trace.StartChild("reserve-stock").End()
+func (t *Trace) StartChild(name string) *Trace {
+	return t.Child().Start(name)
+}
+
 // AddAttribute appends one or more OpenTelemetry attributes to the current trace.
 // This method accepts variadic attribute.KeyValue arguments, allowing the caller
 // to add single or multiple attributes in a single call. It supports both OpenTelemetry
@@ -191,14 +293,39 @@ func Now(ctx context.Context, name, operation string, opts ...Option) *Trace {
 //	)
 //
 // AddAttribute accepts one or more custom TraceFlow attributes and appends them to the trace.
+// Attributes added before Start are applied when the span is created; attributes added
+// after Start are written directly to the active span.
 func (t *Trace) AddAttribute(attrs ...Attribute) *Trace {
+	otelAttrs := make([]attribute.KeyValue, 0, len(attrs))
+
 	for _, attr := range attrs {
-		t.attrs = append(t.attrs, attr.otelAttr)
+		otelAttrs = append(otelAttrs, attr.otelAttr)
 	}
 
+	t.setAttributes(otelAttrs...)
+
 	return t
 }
 
+// setAttributes records attributes on the trace. Before the span has started they are
+// buffered and passed to the span as start options; once the span is active they are
+// set on it directly so that information learned mid-operation is not lost.
+func (t *Trace) setAttributes(attrs ...attribute.KeyValue) {
+	if len(attrs) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.span != nil {
+		t.span.SetAttributes(attrs...)
+		return
+	}
+
+	t.attrs = append(t.attrs, attrs...)
+}
+
 // AddAttributeIf conditionally adds an attribute to the trace based on a boolean condition.
 // If the condition (cond) is true, the attribute specified by the key and value is added to
 // the trace. The method automatically determines the correct OpenTelemetry attribute type
@@ -246,7 +373,7 @@ func (t *Trace) AddAttributeIf(cond bool, key string, value interface{}) *Trace
 		return t
 	}
 
-	t.attrs = append(t.attrs, attr)
+	t.setAttributes(attr)
 
 	return t
 }
@@ -271,6 +398,7 @@ func (t *Trace) AddAttributeIf(cond bool, key string, value interface{}) *Trace
 // do not have direct hierarchical relationships.
 //
 // Notes:
+//   - Links added after Start are added to the active span directly.
 //   - The linked span is represented by its traceflow.SpanContext, which wraps the
 //     OpenTelemetry span context (trace.SpanContext).
 //   - This method returns the Trace object, allowing chaining of additional methods.
@@ -278,6 +406,15 @@ func (t *Trace) AddAttributeIf(cond bool, key string, value interface{}) *Trace
 //     import or use OpenTelemetry types, making tracing integration easier.
 func (t *Trace) AddLink(spanContext SpanContext) *Trace {
 	link := trace.Link{SpanContext: spanContext.otelSpanContext}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.span != nil {
+		t.span.AddLink(link)
+		return t
+	}
+
 	t.links = append(t.links, link)
 
 	return t
@@ -300,10 +437,12 @@ func (t *Trace) AddLink(spanContext SpanContext) *Trace {
 //	trace.SetStatus(codes.Error, "database connection failed")
 //
 // Notes:
-// - Ensure the span is properly started before setting its status.
+// - Ensure the span is properly started before setting its status; before Start this is a no-op.
 // - This method allows the trace to capture both the status code and a descriptive message.
 func (t *Trace) SetStatus(code codes.Code, message string) {
-	t.span.SetStatus(code, message)
+	if span := t.currentSpan(); span != nil {
+		span.SetStatus(code, message)
+	}
 }
 
 // SetSuccess marks the current span as successful by setting its status to codes.Ok,
@@ -364,8 +503,8 @@ func (t *Trace) RecordFailure(err error, message string) {
 //   - The trace ID is useful for tracking and correlating traces across multiple
 //     services in distributed systems.
 func (t *Trace) GetTraceID() string {
-	if t.span != nil {
-		sc := t.span.SpanContext()
+	if span := t.currentSpan(); span != nil {
+		sc := span.SpanContext()
 		if sc.IsValid() {
 			return sc.TraceID().String()
 		}
@@ -409,9 +548,28 @@ func (t *Trace) GetParentID() string {
 //   - Ensure that the context is valid and has been properly initialized before
 //     passing it to other functions or services.
 func (t *Trace) GetContext() context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	return t.ctx
 }
 
+// setContext replaces the context associated with the trace.
+func (t *Trace) setContext(ctx context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ctx = ctx
+}
+
+// currentSpan returns the active span, or nil if the trace has not been started.
+func (t *Trace) currentSpan() trace.Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.span
+}
+
 // End marks the completion of the current span, signaling the end of the operation
 // being traced. This method should be called after the span's operation has completed,
 // allowing the trace to accurately record the duration and any final status or attributes
@@ -441,7 +599,7 @@ func (t *Trace) GetContext() context.Context {
 //     using defer to guarantee they are closed, even in the case of errors.
 //   - Once a span has ended, no additional attributes or status can be set on it.
 func (t *Trace) End() {
-	if t.span != nil {
-		t.span.End()
+	if span := t.currentSpan(); span != nil {
+		span.End()
 	}
 }
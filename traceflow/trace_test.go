@@ -6,10 +6,15 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"sync"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 // // init initializes the OpenTelemetry provider and sets the global propagator.
@@ -345,3 +350,284 @@ func TestWithEnVars(t *testing.T) {
 		t.Errorf("Expected DEPLOYMENT_ENV to be 'production', got %v", trace.attrs[1])
 	}
 }
+
+// newRecordedTrace creates a Trace whose spans are exported synchronously to an
+// in-memory exporter, so tests can inspect exactly what reached the backend.
+func newRecordedTrace(t *testing.T, opts ...Option) (*Trace, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	trace := New(context.Background(), "test-service", opts...)
+	trace.tracer = tp.Tracer("test-service")
+
+	return trace, exporter
+}
+
+// findAttribute returns the value of the attribute with the given key on an exported span.
+func findAttribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+// TestAddAttributeAfterStart tests that attributes added to a started span are exported.
+func TestAddAttributeAfterStart(t *testing.T) {
+	trace, exporter := newRecordedTrace(t)
+
+	trace.AddAttribute(AddString("before", "start"))
+	trace.Start("late-attributes")
+	trace.AddAttribute(AddString("after", "start"))
+	trace.AddAttributeIf(true, "rows", 42)
+	trace.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 exported span, got %d", len(spans))
+	}
+
+	if v, ok := findAttribute(spans[0], "before"); !ok || v.AsString() != "start" {
+		t.Errorf("Expected 'before' attribute to be exported, got %v", v)
+	}
+
+	if v, ok := findAttribute(spans[0], "after"); !ok || v.AsString() != "start" {
+		t.Errorf("Expected 'after' attribute to be exported, got %v", v)
+	}
+
+	if v, ok := findAttribute(spans[0], "rows"); !ok || v.AsInt64() != 42 {
+		t.Errorf("Expected 'rows' attribute to be exported, got %v", v)
+	}
+
+	if len(trace.attrs) != 0 {
+		t.Errorf("Expected no buffered attributes after start, got %d", len(trace.attrs))
+	}
+}
+
+// TestAddHelpersAfterStart tests that the typed Add* helpers write to the active span.
+func TestAddHelpersAfterStart(t *testing.T) {
+	trace, exporter := newRecordedTrace(t)
+
+	trace.Start("late-helpers")
+	trace.AddHTTPResponse(http.StatusCreated, 128).
+		AddDBErrorInfo("deadlock detected", "40P01").
+		AddHTTPHeaders(http.Header{"X-Request-Id": []string{"abc"}}).
+		AddJSON([]byte(`{"ok":true}`))
+	trace.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 exported span, got %d", len(spans))
+	}
+
+	expected := []attribute.KeyValue{
+		attribute.Int("http.status_code", http.StatusCreated),
+		attribute.Int64("http.content_length", 128),
+		attribute.String("db.error_message", "deadlock detected"),
+		attribute.String("db.error_code", "40P01"),
+		attribute.String("http.header.X-Request-Id", "abc"),
+		attribute.String("payload", `{"ok":true}`),
+	}
+
+	for _, attr := range expected {
+		if v, ok := findAttribute(spans[0], attr.Key); !ok || v != attr.Value {
+			t.Errorf("Expected attribute %v to be exported, got %v", attr, v)
+		}
+	}
+}
+
+// TestAddLinkAfterStart tests that links added to a started span are exported.
+func TestAddLinkAfterStart(t *testing.T) {
+	trace, exporter := newRecordedTrace(t)
+
+	_, linked := trace.tracer.Start(context.Background(), "linked-span")
+	linked.End()
+
+	trace.Start("late-link")
+	trace.AddLink(NewSpanContext(linked.SpanContext()))
+	trace.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(spans))
+	}
+
+	links := spans[1].Links
+	if len(links) != 1 || links[0].SpanContext.SpanID() != linked.SpanContext().SpanID() {
+		t.Errorf("Expected span to link to %s, got %v", linked.SpanContext().SpanID(), links)
+	}
+}
+
+// TestStartChild tests that child spans are parented to the span that created them.
+func TestStartChild(t *testing.T) {
+	parent, exporter := newRecordedTrace(t)
+
+	parent.Server().Start("parent-span")
+
+	child := parent.StartChild("child-span")
+	grandchild := child.StartChild("grandchild-span")
+
+	if child.GetParentID() != parent.span.SpanContext().SpanID().String() {
+		t.Errorf("Expected child parent ID %s, got %s", parent.span.SpanContext().SpanID(), child.GetParentID())
+	}
+
+	if grandchild.GetParentID() != child.span.SpanContext().SpanID().String() {
+		t.Errorf("Expected grandchild parent ID %s, got %s", child.span.SpanContext().SpanID(), grandchild.GetParentID())
+	}
+
+	if child.GetTraceID() != parent.GetTraceID() || grandchild.GetTraceID() != parent.GetTraceID() {
+		t.Error("Expected all spans to share the parent's trace ID")
+	}
+
+	grandchild.End()
+	child.End()
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 exported spans, got %d", len(spans))
+	}
+
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+
+	if byName["test-service.child-span"].Parent.SpanID() != byName["test-service.parent-span"].SpanContext.SpanID() {
+		t.Error("Expected exported child span to be parented to the parent span")
+	}
+
+	if byName["test-service.grandchild-span"].Parent.SpanID() != byName["test-service.child-span"].SpanContext.SpanID() {
+		t.Error("Expected exported grandchild span to be parented to the child span")
+	}
+
+	if byName["test-service.child-span"].SpanKind != oteltrace.SpanKindServer {
+		t.Errorf("Expected child to inherit the server span kind, got %v", byName["test-service.child-span"].SpanKind)
+	}
+}
+
+// TestChildWithOptions tests that Child applies options and span kind overrides.
+func TestChildWithOptions(t *testing.T) {
+	parent, exporter := newRecordedTrace(t)
+
+	func() {
+		defer parent.Start("parent-span").End()
+
+		child := parent.Child(WithAttributes(AddString("step", "fetch"))).Client()
+		defer child.Start("fetch").End()
+	}()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(spans))
+	}
+
+	child := spans[0]
+	if child.Name != "test-service.fetch" {
+		t.Fatalf("Expected child span to end first, got %s", child.Name)
+	}
+
+	if child.SpanKind != oteltrace.SpanKindClient {
+		t.Errorf("Expected client span kind, got %v", child.SpanKind)
+	}
+
+	if v, ok := findAttribute(child, "step"); !ok || v.AsString() != "fetch" {
+		t.Errorf("Expected 'step' attribute on child span, got %v", v)
+	}
+
+	if child.Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Error("Expected child span to be parented to the parent span")
+	}
+}
+
+// TestTraceConcurrentUse tests that a Trace can be shared by many goroutines. Run with -race.
+func TestTraceConcurrentUse(t *testing.T) {
+	trace, exporter := newRecordedTrace(t)
+
+	_, linked := trace.tracer.Start(context.Background(), "linked-span")
+	linked.End()
+
+	const workers = 32
+
+	var wg sync.WaitGroup
+
+	// Hammer the trace before and after the span is started
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			trace.AddAttribute(AddInt(fmt.Sprintf("worker.%d", i), i))
+			trace.AddLink(NewSpanContext(linked.SpanContext()))
+			trace.SetStatus(codes.Error, "working")
+
+			if i == workers/2 {
+				trace.Start("concurrent-span")
+			}
+
+			trace.AddAttribute(AddInt("last.worker", i))
+			_ = trace.GetContext()
+			_ = trace.GetTraceID()
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			trace.SetStatus(codes.Error, "done")
+			trace.StartChild("child-span").End()
+			trace.End()
+		}()
+	}
+
+	wg.Wait()
+
+	var parent *tracetest.SpanStub
+
+	spans := exporter.GetSpans()
+	for i := range spans {
+		if spans[i].Name == "test-service.concurrent-span" {
+			parent = &spans[i]
+		}
+	}
+
+	if parent == nil {
+		t.Fatal("Expected the concurrent span to be exported exactly once")
+	}
+
+	if len(spans) != workers+2 {
+		t.Errorf("Expected %d exported spans, got %d", workers+2, len(spans))
+	}
+
+	if _, ok := findAttribute(*parent, "last.worker"); !ok {
+		t.Error("Expected 'last.worker' attribute to be exported")
+	}
+
+	if parent.Status.Code != codes.Error {
+		t.Errorf("Expected status Error, got %v", parent.Status.Code)
+	}
+}
+
+// TestChildWithoutSpanKind tests that children of traces without a span kind can be started.
+func TestChildWithoutSpanKind(t *testing.T) {
+	trace := NewWithoutPropagation(context.Background(), "test-service")
+
+	assert.NotPanics(t, func() {
+		defer trace.Start("parent-span").End()
+		defer trace.StartChild("child-span").End()
+	})
+}
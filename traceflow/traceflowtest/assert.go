@@ -0,0 +1,124 @@
+package traceflowtest
+
+import (
+	"fmt"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Span is a recorded span returned by Recorder.HasSpan, with chainable assertions. When
+// the span was not found, its assertions are skipped, as the failure was already reported.
+type Span struct {
+	tracetest.SpanStub
+
+	recorder *Recorder
+	found    bool
+}
+
+// HasSpan reports a test failure if no recorded span is called name, and returns the first
+// span that is.
+//
+// Example usage:
+//
+//	rec.HasSpan("shop.checkout").HasAttribute("order.id", "1234")
+func (r *Recorder) HasSpan(name string) Span {
+	r.t.Helper()
+
+	for _, span := range r.Spans() {
+		if span.Name == name {
+			return Span{SpanStub: span, recorder: r, found: true}
+		}
+	}
+
+	r.fail("Expected a span named %q", name)
+
+	return Span{SpanStub: tracetest.SpanStub{Name: name}, recorder: r}
+}
+
+// HasAttribute reports a test failure if the span does not have the attribute key with the
+// given value. Go values are compared by their attribute representation, so an int matches
+// an INT64 attribute; attribute.Value can also be passed.
+func (s Span) HasAttribute(key string, value any) Span {
+	s.recorder.t.Helper()
+
+	if !s.found {
+		return s
+	}
+
+	for _, kv := range s.Attributes {
+		if string(kv.Key) != key {
+			continue
+		}
+
+		if !valueEqual(kv.Value, value) {
+			s.recorder.fail("Expected span %q to have attribute %s=%v, got %s", s.Name, key, value, kv.Value.Emit())
+		}
+
+		return s
+	}
+
+	s.recorder.fail("Expected span %q to have attribute %q", s.Name, key)
+
+	return s
+}
+
+// ChildOf reports a test failure if the span is not a direct child of parent.
+func (s Span) ChildOf(parent Span) Span {
+	s.recorder.t.Helper()
+
+	if !s.found || !parent.found {
+		return s
+	}
+
+	if s.Parent.TraceID() != parent.SpanContext.TraceID() || s.Parent.SpanID() != parent.SpanContext.SpanID() {
+		s.recorder.fail("Expected span %q to be a child of %q", s.Name, parent.Name)
+	}
+
+	return s
+}
+
+// StatusIs reports a test failure if the span's status code is not code.
+func (s Span) StatusIs(code codes.Code) Span {
+	s.recorder.t.Helper()
+
+	if !s.found {
+		return s
+	}
+
+	if s.Status.Code != code {
+		s.recorder.fail("Expected span %q to have status %s, got %s", s.Name, code, s.Status.Code)
+	}
+
+	return s
+}
+
+// fail reports a test failure followed by the recorded span tree.
+func (r *Recorder) fail(format string, args ...any) {
+	r.t.Helper()
+
+	tree := r.Tree()
+	if tree == "" {
+		tree = "(no spans recorded)\n"
+	}
+
+	r.t.Errorf("%s\n\nRecorded spans:\n%s", fmt.Sprintf(format, args...), tree)
+}
+
+// valueEqual reports whether the attribute value equals the expected Go value.
+func valueEqual(actual attribute.Value, expected any) bool {
+	switch v := expected.(type) {
+	case attribute.Value:
+		return actual == v
+	case int:
+		return actual == attribute.IntValue(v)
+	case []int:
+		return actual == attribute.IntSliceValue(v)
+	case float32:
+		return actual == attribute.Float64Value(float64(v))
+	default:
+		return reflect.DeepEqual(actual.AsInterface(), expected)
+	}
+}
@@ -0,0 +1,111 @@
+package traceflowtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// failureRecorder captures assertion failures instead of failing the test.
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+func (f *failureRecorder) Helper() {}
+
+func (f *failureRecorder) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+// recordCheckout records a checkout trace with a failed charge.
+func recordCheckout(t *testing.T) (*Recorder, *failureRecorder) {
+	t.Helper()
+
+	failures := &failureRecorder{TB: t}
+	rec := NewRecorder(failures)
+
+	root := rec.Telemetry().New(context.Background(), "shop").Start("checkout")
+
+	charge := root.Child(traceflow.WithAttributes(
+		traceflow.AddString("payment.provider", "stripe"),
+		traceflow.AddInt("amount", 42),
+	)).Start("charge")
+	charge.SetStatus(codes.Error, "card declined")
+	charge.End()
+
+	root.SetStatus(codes.Ok, "")
+	root.End()
+
+	return rec, failures
+}
+
+// TestAssertionsPass tests that assertions matching the recorded spans do not fail.
+func TestAssertionsPass(t *testing.T) {
+	rec, failures := recordCheckout(t)
+
+	root := rec.HasSpan("shop.checkout").StatusIs(codes.Ok)
+	rec.HasSpan("shop.charge").
+		ChildOf(root).
+		StatusIs(codes.Error).
+		HasAttribute("payment.provider", "stripe").
+		HasAttribute("amount", 42).
+		HasAttribute("amount", attribute.Int64Value(42))
+
+	assert.Empty(t, failures.failures)
+}
+
+// TestAssertionsFail tests that mismatches are reported along with the span tree.
+func TestAssertionsFail(t *testing.T) {
+	tests := []struct {
+		name   string
+		assert func(rec *Recorder)
+		want   string
+	}{
+		{
+			name:   "missing span",
+			assert: func(rec *Recorder) { rec.HasSpan("shop.refund").StatusIs(codes.Ok) },
+			want:   `Expected a span named "shop.refund"`,
+		},
+		{
+			name:   "missing attribute",
+			assert: func(rec *Recorder) { rec.HasSpan("shop.checkout").HasAttribute("order.id", "1") },
+			want:   `Expected span "shop.checkout" to have attribute "order.id"`,
+		},
+		{
+			name:   "attribute value",
+			assert: func(rec *Recorder) { rec.HasSpan("shop.charge").HasAttribute("amount", 7) },
+			want:   `Expected span "shop.charge" to have attribute amount=7, got 42`,
+		},
+		{
+			name: "parent",
+			assert: func(rec *Recorder) {
+				rec.HasSpan("shop.checkout").ChildOf(rec.HasSpan("shop.charge"))
+			},
+			want: `Expected span "shop.checkout" to be a child of "shop.charge"`,
+		},
+		{
+			name:   "status",
+			assert: func(rec *Recorder) { rec.HasSpan("shop.checkout").StatusIs(codes.Error) },
+			want:   `Expected span "shop.checkout" to have status Error, got Ok`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, failures := recordCheckout(t)
+
+			tt.assert(rec)
+
+			require.Len(t, failures.failures, 1)
+			assert.Contains(t, failures.failures[0], tt.want)
+			assert.Contains(t, failures.failures[0], "Recorded spans:\nshop.checkout [internal] Ok\n  shop.charge")
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Package traceflowtest records the spans produced by traceflow in tests and provides
+// assertions on them.
+//
+// A Recorder creates an in-memory tracer provider for the duration of a test, without
+// touching the global providers unless asked to with Global. Spans are recorded
+// synchronously, so they can be inspected as soon as they have ended.
+//
+// # Usage
+//
+//	func TestCheckout(t *testing.T) {
+//	    rec := traceflowtest.NewRecorder(t)
+//
+//	    checkout(context.Background(), rec.Telemetry())
+//
+//	    root := rec.HasSpan("shop.checkout").StatusIs(codes.Ok)
+//	    rec.HasSpan("shop.charge").
+//	        ChildOf(root).
+//	        HasAttribute("payment.provider", "stripe")
+//	}
+//
+// Failed assertions report the recorded span tree:
+//
+//	shop.checkout [server] Ok
+//	  shop.reserve-stock [internal] Unset {sku=42}
+//	  shop.charge [client] Error "card declined" {payment.provider=stripe}
+package traceflowtest
@@ -0,0 +1,219 @@
+package traceflowtest
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// updateFlag is the test flag that regenerates golden files instead of comparing against
+	// them. It is namespaced so that it does not clash with an -update flag of the importing
+	// package.
+	updateFlag = "traceflowtest.update"
+
+	// updateEnv is the environment variable that regenerates golden files, for test runners
+	// that cannot pass flags to every package.
+	updateEnv = "TRACEFLOW_UPDATE_GOLDEN"
+)
+
+func init() {
+	if flag.Lookup(updateFlag) == nil {
+		flag.Bool(updateFlag, false, "update traceflowtest golden files")
+	}
+}
+
+// shouldUpdate reports whether golden files are regenerated, through updateFlag or updateEnv.
+func shouldUpdate() bool {
+	if enabled, err := strconv.ParseBool(os.Getenv(updateEnv)); err == nil && enabled {
+		return true
+	}
+
+	f := flag.Lookup(updateFlag)
+
+	return f != nil && f.Value.String() == "true"
+}
+
+// dynamicValue replaces attribute values that change from run to run.
+const dynamicValue = "<dynamic>"
+
+// defaultIgnoredAttributes are the attributes whose values depend on the host, the process
+// or the timing of a run. Keys ending in * match any key with that prefix.
+var defaultIgnoredAttributes = []string{
+	"system.*",
+	"cpu.*",
+	"memory.*",
+	"disk.*",
+	"process.*",
+	"container.*",
+	"kubernetes.*",
+	"k8s.*",
+	"host.*",
+	"os.*",
+	"goroutine.*",
+	"network.latency_ms",
+	"exception.stacktrace",
+}
+
+// GoldenOption defines a functional option for configuring AssertGolden.
+type GoldenOption func(*goldenConfig)
+
+// goldenConfig holds the normalization settings of AssertGolden.
+type goldenConfig struct {
+	ignored []string
+}
+
+// IgnoreAttributes replaces the values of the given attributes with a placeholder, in
+// addition to the host and process attributes ignored by default. Keys ending in * match
+// any key with that prefix.
+func IgnoreAttributes(keys ...string) GoldenOption {
+	return func(c *goldenConfig) {
+		c.ignored = append(c.ignored, keys...)
+	}
+}
+
+// AssertGolden compares the tree of spans with the golden file at path, reporting a test
+// failure with a diff if they differ. Run the tests with -traceflowtest.update, or with
+// TRACEFLOW_UPDATE_GOLDEN=1 in the environment, to write the golden file instead.
+//
+// The tree leaves out everything that changes from run to run: trace and span IDs,
+// timestamps and durations are not included, the values of host, process and runtime
+// attributes are replaced with a placeholder, and attribute values that are IDs of the
+// recorded spans are replaced with <trace-id> or <span-id>. Siblings are ordered by name,
+// then by start time, so that spans started concurrently do not change the tree.
+//
+// Example usage:
+//
+//	rec := traceflowtest.NewRecorder(t)
+//	checkout(ctx, rec.Telemetry())
+//
+//	traceflowtest.AssertGolden(t, rec.Spans(), "testdata/checkout.golden")
+func AssertGolden(t testing.TB, spans tracetest.SpanStubs, path string, opts ...GoldenOption) bool {
+	t.Helper()
+
+	cfg := goldenConfig{ignored: slices.Clone(defaultIgnoredAttributes)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	got := goldenTree(spans, cfg)
+
+	if shouldUpdate() {
+		const (
+			dirmode  = 0o755
+			filemode = 0o644
+		)
+
+		if err := os.MkdirAll(filepath.Dir(path), dirmode); err != nil {
+			t.Errorf("Failed to create golden file directory: %v", err)
+			return false
+		}
+
+		if err := os.WriteFile(path, []byte(got), filemode); err != nil {
+			t.Errorf("Failed to update golden file: %v", err)
+			return false
+		}
+
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("Failed to read golden file, run the test with -traceflowtest.update to create it: %v", err)
+		return false
+	}
+
+	return assert.Equal(t, string(want), got,
+		"Spans do not match golden file %s, run the test with -traceflowtest.update to accept the changes", path)
+}
+
+// goldenTree formats the spans as a tree without the values that change from run to run.
+func goldenTree(spans tracetest.SpanStubs, cfg goldenConfig) string {
+	names := make(map[trace.SpanID]string, len(spans))
+	ids := make(map[string]string, 2*len(spans)) //nolint:mnd
+
+	for _, span := range spans {
+		names[span.SpanContext.SpanID()] = span.Name
+		ids[span.SpanContext.TraceID().String()] = "<trace-id>"
+		ids[span.SpanContext.SpanID().String()] = "<span-id>"
+	}
+
+	normalize := func(attrs []attribute.KeyValue) []attribute.KeyValue {
+		normalized := make([]attribute.KeyValue, len(attrs))
+
+		for i, kv := range attrs {
+			switch {
+			case cfg.ignores(string(kv.Key)):
+				normalized[i] = attribute.String(string(kv.Key), dynamicValue)
+			case ids[kv.Value.Emit()] != "" && kv.Value.Type() == attribute.STRING:
+				normalized[i] = attribute.String(string(kv.Key), ids[kv.Value.Emit()])
+			default:
+				normalized[i] = kv
+			}
+		}
+
+		return normalized
+	}
+
+	format := func(span tracetest.SpanStub) string {
+		span.Attributes = normalize(span.Attributes)
+		lines := []string{formatSpan(span)}
+
+		for _, event := range span.Events {
+			line := fmt.Sprintf("  event %q", event.Name)
+			if len(event.Attributes) > 0 {
+				line += " " + formatAttributes(normalize(event.Attributes))
+			}
+
+			lines = append(lines, line)
+		}
+
+		for _, link := range span.Links {
+			target := "(external)"
+			if name, ok := names[link.SpanContext.SpanID()]; ok {
+				target = name
+			}
+
+			lines = append(lines, "  link -> "+target)
+		}
+
+		return strings.Join(lines, "\n")
+	}
+
+	var b strings.Builder
+
+	writeTree(&b, buildTree(spans, byName), 0, format)
+
+	return b.String()
+}
+
+// byName orders spans by name, then start time.
+func byName(a, b tracetest.SpanStub) int {
+	return cmp.Or(strings.Compare(a.Name, b.Name), a.StartTime.Compare(b.StartTime))
+}
+
+// ignores reports whether the value of the attribute key is left out of golden files.
+func (c goldenConfig) ignores(key string) bool {
+	for _, pattern := range c.ignored {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+
+	return false
+}
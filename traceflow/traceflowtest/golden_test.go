@@ -0,0 +1,115 @@
+package traceflowtest
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wendall-robinson/flowmaster/traceflow"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordGoldenFlow records a checkout flow with host attributes, events, links and IDs.
+func recordGoldenFlow(t testing.TB) *Recorder {
+	t.Helper()
+
+	rec := NewRecorder(t)
+
+	root := rec.Telemetry().New(context.Background(), "shop", traceflow.WithSystemInfo()).Start("checkout")
+	rootContext := trace.SpanContextFromContext(root.GetContext())
+
+	// Started in the opposite order to their names to show that siblings are sorted by name
+	stock := root.Child(traceflow.WithAttributes(traceflow.AddString("sku", "42"))).Start("reserve-stock")
+	stock.AddEvent("cache.miss", time.Now(), traceflow.AddString("cache.key", "sku:42"))
+	stock.End()
+
+	charge := root.Child(traceflow.WithAttributes(
+		traceflow.AddString("checkout.span_id", rootContext.SpanID().String()),
+		traceflow.AddString("payment.provider", "stripe"),
+	)).Start("charge")
+	charge.RecordFailure(errors.New("card declined"), "payment failed")
+	charge.End()
+
+	root.End()
+
+	audit := rec.Telemetry().NewWithoutPropagation(context.Background(), "audit").
+		AddLink(traceflow.NewSpanContext(rootContext)).
+		Start("record")
+	audit.End()
+
+	return rec
+}
+
+// TestAssertGolden tests that a flow matches its golden file across runs.
+func TestAssertGolden(t *testing.T) {
+	for range 2 {
+		rec := recordGoldenFlow(t)
+
+		AssertGolden(t, rec.Spans(), "testdata/checkout.golden")
+	}
+}
+
+// TestAssertGoldenMismatch tests that differences and missing golden files are reported.
+func TestAssertGoldenMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkout.golden")
+	failures := &failureRecorder{TB: t}
+
+	rec := recordGoldenFlow(t)
+
+	assert.False(t, AssertGolden(failures, rec.Spans(), path))
+	require.Len(t, failures.failures, 1)
+	assert.Contains(t, failures.failures[0], "run the test with -traceflowtest.update to create it")
+
+	require.NoError(t, os.WriteFile(path, []byte("shop.checkout [internal] Ok\n"), 0o600))
+
+	assert.False(t, AssertGolden(failures, rec.Spans(), path))
+	require.Len(t, failures.failures, 2)
+	assert.Contains(t, failures.failures[1], "run the test with -traceflowtest.update to accept the changes")
+}
+
+// TestAssertGoldenUpdate tests that -traceflowtest.update writes the golden file.
+func TestAssertGoldenUpdate(t *testing.T) {
+	require.NoError(t, flag.Set(updateFlag, "true"))
+	t.Cleanup(func() { flag.Set(updateFlag, "false") })
+
+	path := filepath.Join(t.TempDir(), "testdata", "checkout.golden")
+	rec := recordGoldenFlow(t)
+
+	require.True(t, AssertGolden(t, rec.Spans(), path))
+	require.NoError(t, flag.Set(updateFlag, "false"))
+
+	assert.True(t, AssertGolden(t, rec.Spans(), path))
+}
+
+// TestAssertGoldenUpdateEnv tests that TRACEFLOW_UPDATE_GOLDEN writes the golden file.
+func TestAssertGoldenUpdateEnv(t *testing.T) {
+	t.Setenv(updateEnv, "1")
+
+	path := filepath.Join(t.TempDir(), "checkout.golden")
+	rec := recordGoldenFlow(t)
+
+	require.True(t, AssertGolden(t, rec.Spans(), path))
+	assert.FileExists(t, path)
+}
+
+// TestIgnoreAttributes tests that custom attributes can be left out of golden files.
+func TestIgnoreAttributes(t *testing.T) {
+	rec := NewRecorder(t)
+
+	rec.Telemetry().New(context.Background(), "shop", traceflow.WithAttributes(
+		traceflow.AddString("request.id", "a1b2"),
+		traceflow.AddString("session.token", "xyz"),
+	)).Start("checkout").End()
+
+	cfg := goldenConfig{}
+	IgnoreAttributes("request.id", "session.*")(&cfg)
+
+	assert.Equal(t, "shop.checkout [internal] Unset {request.id=<dynamic>, session.token=<dynamic>}\n",
+		goldenTree(rec.Spans(), cfg))
+}
@@ -0,0 +1,124 @@
+package traceflowtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wendall-robinson/flowmaster/traceflow"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Recorder records the spans ended during a test.
+type Recorder struct {
+	t         testing.TB
+	exporter  *tracetest.InMemoryExporter
+	provider  *sdktrace.TracerProvider
+	telemetry *traceflow.Telemetry
+}
+
+// RecorderOption defines a functional option for configuring a Recorder.
+type RecorderOption func(*recorderConfig)
+
+// recorderConfig holds the settings of NewRecorder.
+type recorderConfig struct {
+	global bool
+}
+
+// Global also installs the recording tracer provider and its propagator globally, for code
+// under test that uses traceflow.New or another global provider instead of taking a
+// Telemetry. The previous global provider and propagator are restored when the test
+// finishes. Tests using a global recorder must not run in parallel.
+//
+// Example usage:
+//
+//	rec := traceflowtest.NewRecorder(t, traceflowtest.Global())
+//	traceflow.New(ctx, "shop").Start("checkout").End()
+//
+//	rec.HasSpan("shop.checkout")
+func Global() RecorderOption {
+	return func(c *recorderConfig) {
+		c.global = true
+	}
+}
+
+// NewRecorder creates a tracer provider that records every span in memory and shuts it
+// down when the test finishes. Only traces created through Recorder.Telemetry are
+// recorded, and the global tracer provider and propagator are left untouched, so tests
+// using a Recorder can run in parallel. Use Global to record code that uses the global
+// provider.
+//
+// Example usage:
+//
+//	t.Parallel()
+//
+//	rec := traceflowtest.NewRecorder(t)
+//	rec.Telemetry().New(ctx, "shop").Start("checkout").End()
+//
+//	rec.HasSpan("shop.checkout")
+func NewRecorder(t testing.TB, opts ...RecorderOption) *Recorder {
+	t.Helper()
+
+	var cfg recorderConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	r := &Recorder{t: t, exporter: tracetest.NewInMemoryExporter()}
+	r.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSyncer(r.exporter),
+	)
+
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	r.telemetry = traceflow.NewTelemetryWithProvider(r.provider, propagator)
+
+	if cfg.global {
+		previousProvider := otel.GetTracerProvider()
+		previousPropagator := otel.GetTextMapPropagator()
+
+		otel.SetTracerProvider(r.provider)
+		otel.SetTextMapPropagator(propagator)
+
+		t.Cleanup(func() {
+			otel.SetTracerProvider(previousProvider)
+			otel.SetTextMapPropagator(previousPropagator)
+		})
+	}
+
+	t.Cleanup(func() {
+		if err := r.provider.Shutdown(context.Background()); err != nil {
+			t.Errorf("Failed to shut down the recording tracer provider: %v", err)
+		}
+	})
+
+	return r
+}
+
+// TracerProvider returns the provider that records the spans.
+func (r *Recorder) TracerProvider() *sdktrace.TracerProvider {
+	return r.provider
+}
+
+// Telemetry returns a traceflow.Telemetry whose traces are recorded. Pass it to the code
+// under test, or create traces with its methods.
+func (r *Recorder) Telemetry() *traceflow.Telemetry {
+	return r.telemetry
+}
+
+// Spans returns the spans ended so far, in the order they ended.
+func (r *Recorder) Spans() tracetest.SpanStubs {
+	return r.exporter.GetSpans()
+}
+
+// Reset discards the spans recorded so far.
+func (r *Recorder) Reset() {
+	r.exporter.Reset()
+}
+
+// Tree returns the recorded spans formatted as a tree. See Tree.
+func (r *Recorder) Tree() string {
+	return Tree(r.Spans())
+}
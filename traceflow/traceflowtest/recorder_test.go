@@ -0,0 +1,57 @@
+package traceflowtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wendall-robinson/flowmaster/traceflow"
+	"go.opentelemetry.io/otel"
+)
+
+// TestRecorder tests that isolated recorders running in parallel record only the spans of
+// their own Telemetry and leave the global tracer provider untouched.
+func TestRecorder(t *testing.T) {
+	previous := otel.GetTracerProvider()
+
+	for _, service := range []string{"shop", "billing"} {
+		t.Run(service, func(t *testing.T) {
+			t.Parallel()
+
+			rec := NewRecorder(t)
+			assert.Equal(t, previous, otel.GetTracerProvider())
+
+			rec.Telemetry().New(context.Background(), service).Start("checkout").End()
+
+			if assert.Len(t, rec.Spans(), 1) {
+				assert.Equal(t, service+".checkout", rec.Spans()[0].Name)
+			}
+
+			rec.Reset()
+			assert.Empty(t, rec.Spans())
+		})
+	}
+}
+
+// TestGlobalRecorder tests that spans created with the global traceflow functions are
+// recorded and that the previous tracer provider and propagator are restored when the test
+// finishes.
+func TestGlobalRecorder(t *testing.T) {
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+
+	t.Run("recording", func(t *testing.T) {
+		rec := NewRecorder(t, Global())
+		assert.Same(t, rec.TracerProvider(), otel.GetTracerProvider())
+		assert.Equal(t, rec.Telemetry().Propagator(), otel.GetTextMapPropagator())
+
+		traceflow.New(context.Background(), "shop").Start("checkout").End()
+
+		if assert.Len(t, rec.Spans(), 1) {
+			assert.Equal(t, "shop.checkout", rec.Spans()[0].Name)
+		}
+	})
+
+	assert.Equal(t, previousProvider, otel.GetTracerProvider(), "Expected the tracer provider to be restored")
+	assert.Equal(t, previousPropagator, otel.GetTextMapPropagator(), "Expected the propagator to be restored")
+}
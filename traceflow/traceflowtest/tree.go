@@ -0,0 +1,121 @@
+package traceflowtest
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanNode is a span and the spans parented to it.
+type spanNode struct {
+	span     tracetest.SpanStub
+	children []*spanNode
+}
+
+// Tree formats spans as an indented tree, one span per line, giving the span kind, the
+// status and the attributes of each span. Spans whose parent was not recorded are shown
+// at the top level. Siblings are ordered by start time.
+//
+// Example output:
+//
+//	shop.checkout [server] Ok
+//	  shop.charge [client] Error "card declined" {payment.provider=stripe}
+func Tree(spans tracetest.SpanStubs) string {
+	var b strings.Builder
+
+	writeTree(&b, buildTree(spans, byStartTime), 0, formatSpan)
+
+	return b.String()
+}
+
+// buildTree arranges the spans into trees, returning the roots. Siblings are ordered with
+// compare.
+func buildTree(spans tracetest.SpanStubs, compare func(a, b tracetest.SpanStub) int) []*spanNode {
+	type spanKey struct {
+		traceID trace.TraceID
+		spanID  trace.SpanID
+	}
+
+	nodes := make(map[spanKey]*spanNode, len(spans))
+	for _, span := range spans {
+		nodes[spanKey{span.SpanContext.TraceID(), span.SpanContext.SpanID()}] = &spanNode{span: span}
+	}
+
+	var roots []*spanNode
+
+	for _, span := range spans {
+		node := nodes[spanKey{span.SpanContext.TraceID(), span.SpanContext.SpanID()}]
+
+		if parent, ok := nodes[spanKey{span.Parent.TraceID(), span.Parent.SpanID()}]; ok && span.Parent.IsValid() {
+			parent.children = append(parent.children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortNodes(roots, compare)
+
+	return roots
+}
+
+// byStartTime orders spans by start time, then name.
+func byStartTime(a, b tracetest.SpanStub) int {
+	return cmp.Or(a.StartTime.Compare(b.StartTime), strings.Compare(a.Name, b.Name))
+}
+
+// sortNodes orders sibling spans with compare, recursively.
+func sortNodes(nodes []*spanNode, compare func(a, b tracetest.SpanStub) int) {
+	slices.SortStableFunc(nodes, func(a, b *spanNode) int {
+		return compare(a.span, b.span)
+	})
+
+	for _, node := range nodes {
+		sortNodes(node.children, compare)
+	}
+}
+
+// writeTree writes the spans formatted with format, indenting every line of a span by its
+// depth in the tree.
+func writeTree(b *strings.Builder, nodes []*spanNode, depth int, format func(tracetest.SpanStub) string) {
+	for _, node := range nodes {
+		for _, line := range strings.Split(format(node.span), "\n") {
+			b.WriteString(strings.Repeat("  ", depth))
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+
+		writeTree(b, node.children, depth+1, format)
+	}
+}
+
+// formatSpan describes a span on a single line.
+func formatSpan(span tracetest.SpanStub) string {
+	line := fmt.Sprintf("%s [%s] %s", span.Name, span.SpanKind, span.Status.Code)
+
+	if span.Status.Description != "" {
+		line += fmt.Sprintf(" %q", span.Status.Description)
+	}
+
+	if len(span.Attributes) > 0 {
+		line += " " + formatAttributes(span.Attributes)
+	}
+
+	return line
+}
+
+// formatAttributes formats attributes as {key=value, ...}, sorted by key.
+func formatAttributes(attrs []attribute.KeyValue) string {
+	set := attribute.NewSet(attrs...)
+	pairs := make([]string, 0, set.Len())
+
+	for _, kv := range set.ToSlice() {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", kv.Key, kv.Value.Emit()))
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -0,0 +1,72 @@
+package traceflowtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanContext returns a sampled span context for the trace and span numbers.
+func spanContext(traceID, spanID byte) trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{traceID},
+		SpanID:     trace.SpanID{spanID},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+// TestTree tests that spans are nested under their parents and ordered by start time.
+func TestTree(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	spans := tracetest.SpanStubs{
+		{
+			Name:        "shop.charge",
+			SpanContext: spanContext(1, 3),
+			Parent:      spanContext(1, 1),
+			SpanKind:    trace.SpanKindClient,
+			StartTime:   start.Add(2 * time.Millisecond),
+			Status:      sdktrace.Status{Code: codes.Error, Description: "card declined"},
+			Attributes: []attribute.KeyValue{
+				attribute.String("payment.provider", "stripe"),
+				attribute.Int("amount", 42),
+			},
+		},
+		{
+			Name:        "shop.reserve-stock",
+			SpanContext: spanContext(1, 2),
+			Parent:      spanContext(1, 1),
+			SpanKind:    trace.SpanKindInternal,
+			StartTime:   start.Add(time.Millisecond),
+		},
+		{
+			Name:        "shop.checkout",
+			SpanContext: spanContext(1, 1),
+			SpanKind:    trace.SpanKindServer,
+			StartTime:   start,
+			Status:      sdktrace.Status{Code: codes.Ok},
+		},
+		{
+			Name:        "worker.orphan",
+			SpanContext: spanContext(2, 5),
+			Parent:      spanContext(2, 4),
+			SpanKind:    trace.SpanKindInternal,
+			StartTime:   start.Add(time.Second),
+		},
+	}
+
+	want := `shop.checkout [server] Ok
+  shop.reserve-stock [internal] Unset
+  shop.charge [client] Error "card declined" {amount=42, payment.provider=stripe}
+worker.orphan [internal] Unset
+`
+
+	assert.Equal(t, want, Tree(spans))
+	assert.Empty(t, Tree(nil))
+}
@@ -2,23 +2,26 @@ package traceflow
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // InitOption defines a functional option for customizing the Init process
@@ -40,15 +43,39 @@ func (n *noopSpanExporter) Shutdown(context.Context) error {
 // TelemetryBuilder holds configuration for OTEL setup
 type TelemetryBuilder struct {
 	ctx            context.Context
-	traceExporter  sdktrace.SpanExporter
+	exporters      []spanExporter
 	metricExporter metric.Exporter
 	logger         *log.Logger
 	exporter       sdktrace.SpanExporter
 	filePath       string
 	batchTimeout   time.Duration
+	sampler        sdktrace.Sampler
+	parentBased    []sdktrace.ParentBasedSamplerOption
+	useParent      bool
+	errs           []error
+	fallback       bool
+	env            envConfig
+	propagators    []propagation.TextMapPropagator
+	resourceAttrs  []attribute.KeyValue
+	redactions     []RedactionRule
+	batchOptions   []sdktrace.BatchSpanProcessorOption
+	tailSampling   *tailSamplingConfig
+	queue          *spanQueue
+
+	disabledDetectors []string
+
+	shutdownSignals []os.Signal
+	shutdownTimeout time.Duration
 }
 
-// Init initializes OpenTelemetry with optional tracing and metrics.
+// addError records an error encountered while applying an InitOption.
+func (tb *TelemetryBuilder) addError(sentinel, err error) {
+	tb.errs = append(tb.errs, fmt.Errorf("%w: %w", sentinel, err))
+}
+
+// Init initializes OpenTelemetry with optional tracing and metrics and installs the providers
+// globally, where they are used by the package-level functions such as New. Use NewTelemetry
+// to create providers that are not installed globally.
 // It returns the initialized context, a shutdown function, and any encountered error.
 // You can enable metrics by using the `WithMetrics` option, and customize the behavior with additional options.
 //
@@ -60,98 +87,227 @@ type TelemetryBuilder struct {
 // Returns:
 // - A context enriched with tracing capabilities, a shutdown function to clean up resources, and any encountered error.
 //
+// The resource describing the service carries the host, OS, process, container, Kubernetes
+// and service version attributes detected at startup, see WithoutDetector, along with any
+// attributes from WithResourceAttributes.
+//
+// Init honors the standard OTEL_* environment variables (see InitFromEnv); explicit options
+// and a non-empty serviceName take precedence over them. Without a serviceName, the service
+// is named by OTEL_SERVICE_NAME, then by a service.name resource attribute, and only then
+// "unknown_service".
+//
+// If any option fails (for example an OTLP exporter with an unreadable certificate), Init returns
+// the joined option errors and does not install a provider. Use WithFallbackOnError to log the
+// errors and continue with the stdout exporter instead.
+//
 // Example usage:
 //
 //	ctx, shutdown, err := traceflow.Init(ctx, "my-service", traceflow.WithOLTP("http://otel:4317"))
 //	if err != nil {
 //	    log.Fatalf("Failed to initialize OpenTelemetry: %v", err)
 //	}
-//	defer shutdown(ctx)  // Ensure graceful shutdown of the tracing system
+//	defer func() {
+//	    if err := shutdown(ctx); err != nil {  // Ensure graceful shutdown of the tracing system
+//	        log.Printf("Failed to shut down OpenTelemetry: %v", err)
+//	    }
+//	}()
 //
 //	// Your application logic goes here
-//
-//	// Clean up OpenTelemetry before exiting
-func Init(ctx context.Context, serviceName string, opts ...InitOption) (context.Context, func(context.Context), error) {
+func Init(ctx context.Context, serviceName string, opts ...InitOption) (context.Context, func(context.Context) error, error) {
+	tel, err := NewTelemetry(ctx, serviceName, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// OTEL_SDK_DISABLED leaves the global providers untouched
+	if tel.disabled {
+		return ctx, tel.Shutdown, nil
+	}
+
+	// Set global tracer provider, context propagator and meter provider
+	otel.SetTracerProvider(tel.tracerProvider)
+	otel.SetTextMapPropagator(tel.propagator)
+
+	if tel.meterProvider != nil {
+		otel.SetMeterProvider(tel.meterProvider)
+	}
+
+	return ctx, tel.Shutdown, nil
+}
+
+// newTelemetryBuilder loads the environment and applies the options. It returns the option
+// errors unless WithFallbackOnError is given.
+func newTelemetryBuilder(ctx context.Context, opts []InitOption) (*TelemetryBuilder, error) {
 	if ctx == nil {
-		return nil, nil, errors.ErrTraceExporterCreation
+		return nil, errors.ErrTraceExporterCreation
 	}
 
 	builder := &TelemetryBuilder{
-		ctx:    ctx,
-		logger: log.New(os.Stdout, "", log.LstdFlags),
+		ctx:             ctx,
+		logger:          log.New(os.Stdout, "", log.LstdFlags),
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+
+	builder.loadEnv()
+
+	// Options are not applied when the SDK is disabled, so no exporter is created
+	if builder.env.disabled {
+		return builder, nil
 	}
 
 	for _, opt := range opts {
 		opt(builder)
 	}
 
+	builder.applyEnv()
+
+	if err := stderrors.Join(builder.errs...); err != nil {
+		if !builder.fallback {
+			builder.shutdownExporters()
+			return nil, err
+		}
+
+		builder.logger.Printf("Falling back to defaults after option errors: %v", err)
+	}
+
+	return builder, nil
+}
+
+// shutdownExporters releases the exporters created by the options, such as OTLP connections
+// and open files, when the builder is abandoned. Failures are reported to the OpenTelemetry
+// error handler, since the caller is already returning the option errors.
+func (tb *TelemetryBuilder) shutdownExporters() {
+	for _, registered := range tb.exporters {
+		if err := registered.exporter.Shutdown(tb.ctx); err != nil {
+			otel.Handle(fmt.Errorf("%w: %w", errors.ErrTracerProviderShutdown, err))
+		}
+	}
+
+	if tb.metricExporter != nil {
+		if err := tb.metricExporter.Shutdown(tb.ctx); err != nil {
+			otel.Handle(fmt.Errorf("%w: %w", errors.ErrMeterProviderShutdown, err))
+		}
+	}
+}
+
+// build creates the providers described by the builder.
+func (tb *TelemetryBuilder) build(serviceName string) (*Telemetry, error) {
+	if tb.env.disabled {
+		return &Telemetry{
+			tracerProvider: noop.NewTracerProvider(),
+			propagator:     propagation.NewCompositeTextMapPropagator(),
+			disabled:       true,
+		}, nil
+	}
+
+	// Fall back to the no-op exporter set by WithSilentLogger, if any. WithSilentLogger has
+	// always promised to suppress trace output, which the stdout default below would break
+	if len(tb.exporters) == 0 && tb.exporter != nil {
+		tb.addExporter(tb.exporter)
+	}
+
 	// If no trace exporter is provided, default to stdout trace exporter
-	if builder.traceExporter == nil {
-		builder.traceExporter, _ = stdouttrace.New(stdouttrace.WithPrettyPrint())
-		if builder.traceExporter == nil {
-			var err error
-
-			builder.traceExporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
-			if err != nil {
-				return nil, nil, fmt.Errorf("%w: %v", errors.ErrStdOutExporter, err)
-			}
+	if len(tb.exporters) == 0 {
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrStdOutExporter, err)
 		}
+
+		tb.addExporter(exporter)
 	}
 
-	spanProcessor := sdktrace.NewBatchSpanProcessor(
-		builder.traceExporter,
-		sdktrace.WithBatchTimeout(builder.batchTimeout),
-	)
+	if serviceName == "" {
+		serviceName = tb.env.serviceName
+	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithSpanProcessor(spanProcessor),
-		sdktrace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(serviceName),
-		)),
-	)
+	// A service.name from OTEL_RESOURCE_ATTRIBUTES or WithResourceAttributes wins over the default
+	if serviceName == "" && !slices.ContainsFunc(tb.resourceAttrs, func(attr attribute.KeyValue) bool {
+		return attr.Key == semconv.ServiceNameKey
+	}) {
+		serviceName = defaultServiceName
+	}
+
+	// Explicit attributes override detected ones, and the service name overrides both
+	attrs := append(tb.detectResource(), tb.resourceAttrs...)
+	if serviceName != "" {
+		attrs = append(attrs, semconv.ServiceNameKey.String(serviceName))
+	}
 
-	// Set global tracer provider and context propagator
-	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
+	res := resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(tb.buildSampler()),
+		sdktrace.WithResource(res),
+	}
+
+	// Each exporter gets its own batch processor, so one failing backend does not block the others
+	processors := make([]sdktrace.SpanProcessor, 0, len(tb.exporters))
+	for _, exporter := range tb.exporters {
+		processors = append(processors, exporter.spanProcessor(tb))
+	}
+
+	// Tail sampling decides on whole traces before any exporter sees them
+	if tb.tailSampling != nil {
+		processors = []sdktrace.SpanProcessor{newTailSamplingProcessor(*tb.tailSampling, processors...)}
+	}
+
+	for _, processor := range processors {
+		providerOpts = append(providerOpts, sdktrace.WithSpanProcessor(processor))
+	}
+
+	tp := sdktrace.NewTracerProvider(providerOpts...)
+
+	propagators := tb.propagators
+	if propagators == nil {
+		propagators = defaultPropagators()
+	}
+
+	tel := &Telemetry{
+		tracerProvider: tp,
+		propagator:     propagation.NewCompositeTextMapPropagator(propagators...),
+		queue:          tb.queue,
+	}
 
 	// Optional metrics setup
-	var mpShutdown func(context.Context) error
-
-	if builder.metricExporter != nil {
-		mp := metric.NewMeterProvider(
-			metric.WithReader(metric.NewPeriodicReader(builder.metricExporter)),
-			metric.WithResource(resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String(serviceName),
-			)),
+	var mp *metric.MeterProvider
+
+	if tb.metricExporter != nil {
+		mp = metric.NewMeterProvider(
+			metric.WithReader(metric.NewPeriodicReader(tb.metricExporter)),
+			metric.WithResource(res),
 		)
 
-		otel.SetMeterProvider(mp)
-		mpShutdown = mp.Shutdown
+		tel.meterProvider = mp
+	}
+
+	stopSignals := func() {}
+	if len(tb.shutdownSignals) > 0 {
+		stopSignals = tel.watchSignals(tb.shutdownSignals, tb.shutdownTimeout, tb.logger)
 	}
 
 	// Shutdown function for cleanup
-	shutdown := func(ctx context.Context) {
+	tel.shutdown = func(ctx context.Context) error {
+		stopSignals()
+
+		var errs []error
+
 		if err := tp.Shutdown(ctx); err != nil {
-			builder.logger.Printf("Error shutting down tracer provider: %v", err)
+			errs = append(errs, fmt.Errorf("%w: %w", errors.ErrTracerProviderShutdown, err))
 		}
 
 		// If metrics were enabled, shut down the meter provider
-		if mpShutdown != nil {
-			if err := mpShutdown(ctx); err != nil {
-				builder.logger.Printf("Error shutting down meter provider: %v", err)
+		if mp != nil {
+			if err := mp.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %w", errors.ErrMeterProviderShutdown, err))
 			}
 		}
+
+		return stderrors.Join(errs...)
 	}
 
-	builder.logger.Println("OpenTelemetry initialized successfully")
+	tb.logger.Println("OpenTelemetry initialized successfully")
 
-	return builder.ctx, shutdown, nil
+	return tel, nil
 }
 
 // WithMetrics enables metric collection and sets up the metric exporter.
@@ -160,58 +316,96 @@ func WithMetrics() InitOption {
 		// Set up the default stdout metric exporter for development
 		exporter, err := stdoutmetric.New(stdoutmetric.WithPrettyPrint())
 		if err != nil {
-			tb.logger.Printf("Error setting up metrics exporter: %v", err)
+			tb.addError(errors.ErrMetricExporterCreation, err)
+			return
 		}
 
 		tb.metricExporter = exporter
 	}
 }
 
-// WithOLTP sets the OLTP exporter to send traces to an OpenTelemetry collector.
-func WithOLTP(target string) InitOption {
+// WithOLTP adds an OLTP exporter that sends traces to an OpenTelemetry collector over gRPC.
+// Spans are sent without TLS unless a TLS option, such as WithOTLPCACertificate or
+// WithOTLPClientCertificate, is given.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithOLTP("otel.example.com:4317",
+//	        traceflow.WithOTLPCACertificate("/etc/otel/ca.pem"),
+//	        traceflow.WithOTLPClientCertificate("/etc/otel/client.pem", "/etc/otel/client-key.pem"),
+//	    ),
+//	)
+func WithOLTP(target string, opts ...OTLPOption) InitOption {
 	return func(tb *TelemetryBuilder) {
 		tb.logger.Println("Using OTLP exporter")
 
-		exp, err := otlptracegrpc.New(tb.ctx, otlptracegrpc.WithInsecure(), otlptracegrpc.WithEndpoint(target))
+		exp, err := newOTLPGRPCExporter(tb.ctx, target, opts)
 		if err != nil {
-			tb.logger.Printf("Failed to create OLTP exporter: %v", err)
+			tb.addError(errors.ErrTraceExporterCreation, err)
 			return
 		}
 
-		tb.traceExporter = exp
+		tb.addExporter(exp, newOTLPConfig(opts).exporterOptions()...)
 	}
 }
 
-// WithFileLogging sets up a file exporter to write trace logs to a file.
-func WithFileLogging(filePath string) InitOption {
+// WithFileLogging adds a file exporter that writes one compact JSON span per line to
+// filePath. The file can be rotated by size and age with FileOptions. Library log messages
+// are not written to the file; use WithLogger to redirect them.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithFileLogging("/var/log/traces.jsonl",
+//	        traceflow.WithFileMaxSize(100<<20),
+//	        traceflow.WithFileMaxAge(24*time.Hour),
+//	        traceflow.WithFileMaxBackups(7),
+//	        traceflow.WithFileCompression(),
+//	    ),
+//	)
+func WithFileLogging(filePath string, opts ...FileOption) InitOption {
 	return func(tb *TelemetryBuilder) {
-		const filemode = 0o644
-
 		tb.filePath = filePath
 
-		// Open or create the file for appending trace logs
-		file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filemode)
+		exporter, err := NewFileExporter(filePath, opts...)
 		if err != nil {
-			tb.logger.Printf("Error creating file exporter: %v", err)
+			tb.addError(errors.ErrFileExporterCreation, err)
 			return
 		}
 
-		// Set the logger output to file, if needed
-		tb.logger.SetOutput(file)
+		tb.addExporter(exporter)
+	}
+}
 
-		// Create the exporter to write to the file
-		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint(), stdouttrace.WithWriter(file))
-		if err != nil {
-			tb.logger.Printf("Error creating file exporter: %v", err)
-			return
-		}
+// WithSpanExporter adds a custom span exporter, such as an in-memory exporter for tests.
+// Spans are sent to every exporter added with WithSpanExporter, WithOLTP, WithOTLPHTTP or
+// WithFileLogging.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithOLTP("otel:4317"),
+//	    traceflow.WithSpanExporter(auditExporter, traceflow.WithSpanFilter(isAuditSpan)),
+//	)
+func WithSpanExporter(exporter sdktrace.SpanExporter, opts ...ExporterOption) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.addExporter(exporter, opts...)
+	}
+}
 
-		// Set the traceExporter in the TelemetryBuilder
-		tb.traceExporter = exporter // Fixing this to use traceExporter
+// WithFallbackOnError makes Init log option errors and continue with the remaining
+// configuration, falling back to the stdout exporter when no exporter could be created.
+// Without it, Init returns the option errors.
+func WithFallbackOnError() InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.fallback = true
 	}
 }
 
-// WithSilentLogger sets a no-op logger and no-op span exporter, useful for testing.
+// WithSilentLogger sets a no-op logger and no-op span exporter, useful for testing. The
+// no-op exporter replaces the stdout default when no other exporter is configured, and is
+// not used alongside exporters added by other options.
 func WithSilentLogger() InitOption {
 	return func(tb *TelemetryBuilder) {
 		tb.logger = log.New(io.Discard, "", 0) // Silence the logger
@@ -233,3 +427,17 @@ func WithBatchTimeout(timeout time.Duration) InitOption {
 		tb.batchTimeout = timeout
 	}
 }
+
+// WithBatchOptions passes additional options, such as the queue and export batch sizes, to
+// the BatchSpanProcessor.
+//
+// Example usage:
+//
+//	ctx, shutdown, err := traceflow.Init(ctx, "my-service",
+//	    traceflow.WithBatchOptions(sdktrace.WithMaxQueueSize(4096), sdktrace.WithMaxExportBatchSize(1024)),
+//	)
+func WithBatchOptions(opts ...sdktrace.BatchSpanProcessorOption) InitOption {
+	return func(tb *TelemetryBuilder) {
+		tb.batchOptions = append(tb.batchOptions, opts...)
+	}
+}
@@ -3,10 +3,16 @@ package traceflow
 import (
 	"bytes"
 	"context"
+	"log"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tferrors "github.com/wendall-robinson/flowmaster/traceflow/internal/errors"
 	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace/noop"
 )
 
@@ -69,3 +75,176 @@ func TestInitWithSilentLogger(t *testing.T) {
 		shutdown(newCtx)
 	}, "Expected shutdown to execute without panicking")
 }
+
+// TestWithSilentLoggerSuppressesSpans tests that WithSilentLogger alone exports to its no-op
+// exporter rather than printing spans through the stdout default, and that it does not
+// replace other exporters.
+func TestWithSilentLoggerSuppressesSpans(t *testing.T) {
+	builder, err := newTelemetryBuilder(context.Background(), []InitOption{WithSilentLogger()})
+	require.NoError(t, err)
+
+	tel, err := builder.build("test-service")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { tel.Shutdown(context.Background()) })
+
+	require.Len(t, builder.exporters, 1)
+	assert.IsType(t, &noopSpanExporter{}, builder.exporters[0].exporter)
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	builder, err = newTelemetryBuilder(context.Background(), []InitOption{WithSilentLogger(), WithSpanExporter(exporter)})
+	require.NoError(t, err)
+
+	tel, err = builder.build("test-service")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { tel.Shutdown(context.Background()) })
+
+	require.Len(t, builder.exporters, 1)
+	assert.Same(t, exporter, builder.exporters[0].exporter)
+}
+
+// TestWithSpanExporter tests that spans are sent to a custom exporter, and that
+// WithSilentLogger does not replace it.
+func TestWithSpanExporter(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, shutdown, err := Init(context.Background(), "test-service", WithSilentLogger(), WithSpanExporter(exporter))
+	assert.NoError(t, err)
+
+	New(ctx, "test-service").Start("exported").End()
+
+	tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	if assert.True(t, ok, "Expected Init to install an SDK tracer provider") {
+		assert.NoError(t, tp.ForceFlush(ctx))
+	}
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "test-service.exported", spans[0].Name)
+	}
+
+	shutdown(ctx)
+}
+
+// TestInitOptionErrors tests that failing options are returned from Init wrapped in their
+// sentinel error instead of silently falling back to stdout.
+func TestInitOptionErrors(t *testing.T) {
+	missingCA := filepath.Join(t.TempDir(), "missing-ca.pem")
+	missingDir := filepath.Join(t.TempDir(), "missing", "traces.log")
+
+	tests := []struct {
+		name     string
+		option   InitOption
+		sentinel error
+	}{
+		{
+			name:     "OTLP gRPC",
+			option:   WithOLTP("localhost:4317", WithOTLPCACertificate(missingCA)),
+			sentinel: tferrors.ErrTraceExporterCreation,
+		},
+		{
+			name:     "OTLP HTTP",
+			option:   WithOTLPHTTP("https://localhost:4318", WithOTLPCACertificate(missingCA)),
+			sentinel: tferrors.ErrTraceExporterCreation,
+		},
+		{
+			name:     "file logging",
+			option:   WithFileLogging(missingDir),
+			sentinel: tferrors.ErrFileExporterCreation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, shutdown, err := Init(context.Background(), "test-service", WithSilentLogger(), tt.option)
+
+			assert.ErrorIs(t, err, tt.sentinel)
+			assert.Nil(t, ctx)
+			assert.Nil(t, shutdown)
+		})
+	}
+}
+
+// TestInitOptionErrorsJoined tests that every failing option is reported.
+func TestInitOptionErrorsJoined(t *testing.T) {
+	missingCA := filepath.Join(t.TempDir(), "missing-ca.pem")
+	missingDir := filepath.Join(t.TempDir(), "missing", "traces.log")
+
+	_, _, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithOLTP("localhost:4317", WithOTLPCACertificate(missingCA)),
+		WithFileLogging(missingDir),
+	)
+
+	assert.ErrorIs(t, err, tferrors.ErrTraceExporterCreation)
+	assert.ErrorIs(t, err, tferrors.ErrFileExporterCreation)
+}
+
+// shutdownTrackingExporter records whether it was shut down.
+type shutdownTrackingExporter struct {
+	tracetest.InMemoryExporter
+	shutdown bool
+}
+
+func (e *shutdownTrackingExporter) Shutdown(ctx context.Context) error {
+	e.shutdown = true
+	return e.InMemoryExporter.Shutdown(ctx)
+}
+
+// TestInitOptionErrorsShutdownExporters tests that exporters created by the options that
+// succeeded are shut down when Init returns the errors of the others.
+func TestInitOptionErrorsShutdownExporters(t *testing.T) {
+	exporter := &shutdownTrackingExporter{}
+	missingDir := filepath.Join(t.TempDir(), "missing", "traces.log")
+
+	_, _, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithSpanExporter(exporter),
+		WithFileLogging(missingDir),
+	)
+
+	assert.ErrorIs(t, err, tferrors.ErrFileExporterCreation)
+	assert.True(t, exporter.shutdown, "Expected the exporter to be shut down")
+}
+
+// TestInitWithFallbackOnError tests that WithFallbackOnError restores the old behavior of
+// logging option errors and continuing.
+func TestInitWithFallbackOnError(t *testing.T) {
+	var logOutput bytes.Buffer
+
+	missingCA := filepath.Join(t.TempDir(), "missing-ca.pem")
+
+	ctx, shutdown, err := Init(context.Background(), "test-service",
+		WithSilentLogger(),
+		WithLogger(log.New(&logOutput, "", 0)),
+		WithOLTP("localhost:4317", WithOTLPCACertificate(missingCA)),
+		WithFallbackOnError(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NotNil(t, ctx)
+	assert.NoError(t, shutdown(ctx))
+	assert.Contains(t, logOutput.String(), tferrors.ErrTraceExporterCreation.Error())
+}
+
+// TestInitShutdownError tests that the shutdown function reports provider shutdown errors.
+func TestInitShutdownError(t *testing.T) {
+	ctx, shutdown, err := Init(context.Background(), "test-service", WithSilentLogger())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err = shutdown(cancelled)
+	assert.ErrorIs(t, err, tferrors.ErrTracerProviderShutdown)
+	assert.ErrorIs(t, err, context.Canceled)
+}
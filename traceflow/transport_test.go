@@ -0,0 +1,181 @@
+package traceflow
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// TestTransportInjectsContext tests that client spans are created per request, parented
+// to the request context, and propagated to the server.
+func TestTransportInjectsContext(t *testing.T) {
+	exporter := useRecordingProvider(t)
+	useTraceContextPropagator(t)
+
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	parent := Now(context.Background(), "test-service", "parent")
+	client := &http.Client{Transport: NewTransport(nil, WithTransportService("test-client"))}
+
+	req, err := http.NewRequestWithContext(parent.GetContext(), http.MethodGet, server.URL+"/path", nil)
+	assert.NoError(t, err)
+
+	resp, err := client.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	parent.End()
+
+	assert.Empty(t, req.Header.Get("Traceparent"), "Expected the caller's request to be left untouched")
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+
+	client0 := spans[0]
+	assert.Equal(t, "test-client.GET", client0.Name)
+	assert.Equal(t, oteltrace.SpanKindClient, client0.SpanKind)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), client0.Parent.SpanID())
+	assert.Contains(t, traceparent, client0.SpanContext.SpanID().String())
+
+	expected := []attribute.KeyValue{
+		attribute.String("http.method", http.MethodGet),
+		attribute.String("http.url", server.URL+"/path"),
+		attribute.Int("http.status_code", http.StatusOK),
+		attribute.Int64("http.content_length", 5),
+	}
+
+	for _, attr := range expected {
+		if v, ok := findAttribute(client0, attr.Key); !ok || v != attr.Value {
+			t.Errorf("Expected attribute %v, got %v", attr, v)
+		}
+	}
+}
+
+// TestTransportAttributes tests that credentials in the URL are not recorded and that the
+// status code is recorded once.
+func TestTransportAttributes(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL + "/path?q=1")
+	require.NoError(t, err)
+
+	target.User = url.UserPassword("alice", "secret")
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, target.String(), nil)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: NewTransport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+
+	v, _ := findAttribute(spans[0], "http.url")
+	assert.Equal(t, server.URL+"/path?q=1", v.AsString())
+	assert.Equal(t, "alice:secret", req.URL.User.String(), "Expected the caller's URL to be left untouched")
+
+	var statusCodes int
+
+	for _, attr := range spans[0].Attributes {
+		if attr.Key == "http.status_code" {
+			statusCodes++
+		}
+	}
+
+	assert.Equal(t, 1, statusCodes, "Expected http.status_code to be recorded once")
+}
+
+// TestTransportEndsOnBodyClose tests that the span stays open until the body is closed.
+func TestTransportEndsOnBodyClose(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("streamed"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport)}
+
+	resp, err := client.Get(server.URL) //nolint:noctx
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Empty(t, exporter.GetSpans(), "Expected the span to remain open until the body is closed")
+
+	time.Sleep(10 * time.Millisecond)
+
+	closedAt := time.Now()
+
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body.Close()
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+
+	assert.False(t, spans[0].EndTime.Before(closedAt), "Expected the span to end when the body was closed")
+}
+
+// TestTransportFailures tests that 5xx responses and transport errors mark spans as Error.
+func TestTransportFailures(t *testing.T) {
+	exporter := useRecordingProvider(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	resp, err := client.Get(server.URL) //nolint:noctx
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+
+	server.Close()
+
+	_, err = client.Get(server.URL) //nolint:noctx,bodyclose
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+
+	for _, span := range spans {
+		assert.Equal(t, codes.Error, span.Status.Code)
+	}
+
+	if v, _ := findAttribute(spans[0], "http.status_code"); v.AsInt64() != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code 503, got %v", v)
+	}
+
+	assert.NotEmpty(t, spans[1].Events, "Expected the transport error to be recorded")
+}